@@ -0,0 +1,118 @@
+package quickcopy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+)
+
+func isArrayType(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Array)
+	return ok
+}
+
+// getArrayCopyFuncName 获取数组拷贝函数名，数组和切片互转也使用这个名字
+func getArrayCopyFuncName(srcType, dstType string) string {
+	return fmt.Sprintf("copy%sFrom%s", sanitizeTypeName(dstType), sanitizeTypeName(srcType))
+}
+
+// handleArrayConversion 处理 [N]T -> [M]U、[N]T -> []U 以及 []T -> [N]U。
+// 目标数组比源数据短时截断，比源数据长时剩余元素保持零值
+func handleArrayConversion(srcType, dstType types.Type, opts copyOptions, mode errMode, env *typeEnv) (conversion, bool) {
+	env = env.helperEnv()
+	srcElem := getElementType(srcType)
+	dstElem := getElementType(dstType)
+
+	elemConv, ok := getTypeConversion(srcElem, dstElem, opts, mode, env)
+	if !ok {
+		env.debugf("No element conversion for %s to %s", env.typeString(srcType), env.typeString(dstType))
+		return conversion{}, false
+	}
+
+	funcName := generateArrayCopyFunc(srcType, dstType, elemConv, mode, env)
+	if funcName == "" {
+		return conversion{}, false
+	}
+	if elemConv.Fallible {
+		return conversion{Func: funcName, Fallible: true, ErrSep: errSepNested, Opts: elemConv.Opts}, true
+	}
+	return conversion{Func: funcName, Opts: elemConv.Opts}, true
+}
+
+func generateArrayCopyFunc(srcType, dstType types.Type, elemConv conversion, mode errMode, env *typeEnv) string {
+	// 辅助函数使用无名类型，具名数组（如 uuid.UUID）可以直接传入
+	srcName := env.typeString(srcType.Underlying())
+	dstName := env.typeString(dstType.Underlying())
+	funcName := getArrayCopyFuncName(srcName, dstName) + elemConv.Opts
+	if elemConv.Fallible {
+		funcName += mode.suffix()
+	}
+
+	if env.scope.hasHelper(funcName) {
+		env.debugf("Array function %s already generated", funcName)
+		return funcName
+	}
+
+	// 目标是切片时按源数组长度分配
+	result := fmt.Sprintf("(dst %s)", dstName)
+	init := ""
+	if !isArrayType(dstType) {
+		result = dstName
+		init = fmt.Sprintf("dst := make(%s, len(src))\n", dstName)
+	}
+
+	// 元素无需转换时使用内置的 copy
+	var loop string
+	ret := "dst"
+	switch {
+	case elemConv == (conversion{}):
+		loop = "copy(dst[:], src[:])"
+	case elemConv.Fallible:
+		// 元素转换可能失败时返回带下标的错误
+		if isArrayType(dstType) {
+			result = fmt.Sprintf("(dst %s, err error)", dstName)
+		} else {
+			result = fmt.Sprintf("(%s, error)", dstName)
+			init += "var err error\n"
+		}
+		if mode == errJoin {
+			init += "var errs []error\n"
+		}
+		loop = fmt.Sprintf("for i := 0; i < len(src) && i < len(dst); i++ {\nif %s; err != nil {\n%s\n}\n}",
+			generateFallibleConversion("src[i]", "dst[i]", elemConv),
+			mode.onErr("dst, ", "[%d]", elemConv, env, "i"))
+		ret = "dst, " + mode.returnErr(env)
+	default:
+		loop = fmt.Sprintf("for i := 0; i < len(src) && i < len(dst); i++ {\n%s\n}",
+			generateElementConversion("src[i]", "dst[i]", elemConv))
+	}
+
+	code := fmt.Sprintf(`
+	package main
+// %s 是自动生成的数组拷贝函数
+func %s(src %s) %s {
+	%s%s
+	return %s
+}`, funcName, funcName, srcName, result, init, loop, ret)
+
+	// 安全解析生成的代码
+	fset := token.NewFileSet()
+	parsedFile, err := parser.ParseFile(fset, "", code, parser.ParseComments)
+	if err != nil {
+		env.errorf(token.NoPos, "parse generated array function: %v", err)
+		return ""
+	}
+
+	if len(parsedFile.Decls) == 0 {
+		env.errorf(token.NoPos, "generated array function is empty")
+		return ""
+	}
+
+	if fn, ok := parsedFile.Decls[0].(*ast.FuncDecl); ok {
+		addGeneratedFunction(funcName, fn, env)
+		return funcName
+	}
+	return ""
+}
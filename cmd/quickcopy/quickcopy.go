@@ -1,8 +1,119 @@
 package main
 
-import "github.com/antlabs/quickcopy"
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/antlabs/quickcopy"
+)
+
+const usage = `用法: quickcopy [flags] [command] [packages]
+
+命令:
+  generate  生成拷贝函数（默认）
+  check     检查生成的代码是否过期，有差异时输出 diff 并以 1 退出，用于 CI
+  diff      输出重新生成后的 diff，不修改文件
+  explain   输出每个拷贝函数的字段映射
+  clean     删除生成的 zz_quickcopy_gen*.go 文件
+
+packages 和 go list 相同，如 ./...、./internal/dto，默认 ./...
+
+flags（可以写在命令前面或者后面）:
+`
+
+// listFlag 是逗号分隔、可以重复指定的列表参数
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+var commands = map[string]bool{"generate": true, "check": true, "diff": true, "explain": true, "clean": true}
 
 func main() {
-	// quickcopy.Main("/Users/guonaihong/my-github/quickcopy/mytest/example")
-	quickcopy.Main(".")
+	var (
+		cfg       quickcopy.Config
+		exclude   listFlag
+		tags      listFlag
+		dryRun    bool
+		matchTags listFlag
+		strip     listFlag
+	)
+	fs := flag.NewFlagSet("quickcopy", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	fs.BoolVar(&cfg.Verbose, "v", false, "输出生成过程的详细日志")
+	fs.BoolVar(&dryRun, "dry-run", false, "只输出需要修改或删除的文件，不写入")
+	fs.Var(&exclude, "exclude", "不处理的文件或目录，逗号分隔的 glob，如 internal/legacy,*_mock.go")
+	fs.Var(&tags, "tags", "构建标签，逗号分隔")
+	fs.BoolVar(&cfg.Defaults.AllowNarrow, "allow-narrow", false, "默认允许大类型转小类型")
+	fs.BoolVar(&cfg.Defaults.IgnoreCase, "ignore-case", false, "默认忽略字段名大小写")
+	fs.BoolVar(&cfg.Defaults.SingleToSlice, "single-to-slice", false, "默认允许单个元素转切片")
+	fs.BoolVar(&cfg.Defaults.JoinErrors, "join-errors", false, "默认收集所有转换错误后返回")
+	fs.BoolVar(&cfg.Defaults.Strict, "strict", false, "默认要求所有目标字段都有来源")
+	fs.BoolVar(&cfg.Defaults.StrictSource, "strict-source", false, "默认要求所有源字段都被使用，否则只给出警告")
+	fs.BoolVar(&cfg.Defaults.Flatten, "flatten", false, "默认按前缀展开、合并嵌套结构体的字段，如 AddressCity 和 Address.City")
+	fs.Var(&matchTags, "match-tag", "默认按这些标签的名字匹配字段，逗号分隔，如 json,db")
+	fs.StringVar(&cfg.Defaults.Match, "match", "", "默认的字段名匹配方式：exact 或者 normalized")
+	fs.Var(&strip, "strip", "默认匹配字段名前去掉的前缀、后缀，逗号分隔，如 Src,DTO,PB")
+
+	// flag 可以出现在命令前后，命令之后剩下的参数是包
+	fs.Parse(os.Args[1:])
+	cmd := "generate"
+	args := fs.Args()
+	if len(args) > 0 && commands[args[0]] {
+		cmd = args[0]
+		fs.Parse(args[1:])
+		args = fs.Args()
+	}
+	cfg.Dir = "."
+	cfg.Patterns = args
+	cfg.Exclude = exclude
+	cfg.Tags = tags
+	cfg.Defaults.MatchTags = matchTags
+	cfg.Defaults.Strip = strip
+
+	log.SetFlags(0)
+	log.SetPrefix("quickcopy: ")
+
+	var err error
+	switch cmd {
+	case "generate":
+		var paths []string
+		paths, err = quickcopy.Run(cfg, dryRun)
+		for _, p := range paths {
+			fmt.Println(p)
+		}
+	case "check":
+		var stale bool
+		stale, err = quickcopy.Diff(cfg, os.Stdout)
+		if err == nil && stale {
+			os.Exit(1)
+		}
+	case "diff":
+		_, err = quickcopy.Diff(cfg, os.Stdout)
+	case "explain":
+		err = quickcopy.Explain(cfg, os.Stdout)
+	case "clean":
+		var paths []string
+		paths, err = quickcopy.Clean(cfg, dryRun)
+		for _, p := range paths {
+			fmt.Println(p)
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,132 @@
+package quickcopy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const converterDirective = "// :quickcopy-converter"
+
+// converter 是用户通过 // :quickcopy-converter 注册的转换函数
+type converter struct {
+	name     string
+	pkg      *types.Package
+	fallible bool // 签名为 func(A) (B, error)
+}
+
+// isDirective 判断注释是否以指令 name 开头，指令后面只能是空白或者结束
+func isDirective(text, name string) bool {
+	rest, ok := strings.CutPrefix(text, name)
+	return ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t')
+}
+
+// loadConverters 收集包本身以及直接导入的包中注册的转换函数
+func (s *session) loadConverters(pkg *packages.Package) map[string]*converter {
+	if table, ok := s.converters[pkg.ID]; ok {
+		return table
+	}
+
+	table := make(map[string]*converter)
+	s.collectConverters(pkg, table, true)
+	for _, imp := range pkg.Imports {
+		s.collectConverters(imp, table, false)
+	}
+	s.converters[pkg.ID] = table
+	return table
+}
+
+// collectConverters 收集 pkg 中注册的转换函数，local 为 false 时 pkg 是导入的包，未导出的转换函数无法调用
+func (s *session) collectConverters(pkg *packages.Package, table map[string]*converter, local bool) {
+	if pkg.TypesInfo == nil {
+		return
+	}
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Doc == nil || funcDecl.Recv != nil {
+				continue
+			}
+			if !hasDirective(funcDecl.Doc, converterDirective) {
+				continue
+			}
+
+			fn, _ := pkg.TypesInfo.Defs[funcDecl.Name].(*types.Func)
+			if fn == nil {
+				continue
+			}
+			if !local && !fn.Exported() {
+				s.res.warnf(pkg.Fset.Position(fn.Pos()), "converter %s is not exported, skipping it outside package %s", fn.FullName(), pkg.Types.Name())
+				continue
+			}
+			src, dst, fallible, ok := converterSignature(fn.Type().(*types.Signature))
+			if !ok {
+				s.res.warnf(pkg.Fset.Position(fn.Pos()), "converter %s must be func(A) B or func(A) (B, error)", fn.FullName())
+				continue
+			}
+
+			key := pairKey(src, dst)
+			if old, ok := table[key]; ok {
+				s.res.warnf(pkg.Fset.Position(fn.Pos()), "converter %s overrides %s for %s", fn.Name(), old.name, key)
+			}
+			s.debugf("Found converter %s: %s", fn.FullName(), key)
+			table[key] = &converter{name: fn.Name(), pkg: fn.Pkg(), fallible: fallible}
+		}
+	}
+}
+
+func hasDirective(doc *ast.CommentGroup, name string) bool {
+	for _, comment := range doc.List {
+		if isDirective(comment.Text, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// converterSignature 检查转换函数的签名，返回源类型、目标类型以及是否返回 error
+func converterSignature(sig *types.Signature) (src, dst types.Type, fallible, ok bool) {
+	if sig.TypeParams().Len() != 0 || sig.Variadic() || sig.Params().Len() != 1 {
+		return nil, nil, false, false
+	}
+	results := sig.Results()
+	switch results.Len() {
+	case 1:
+		return sig.Params().At(0).Type(), results.At(0).Type(), false, true
+	case 2:
+		if !types.Identical(results.At(1).Type(), types.Universe.Lookup("error").Type()) {
+			return nil, nil, false, false
+		}
+		return sig.Params().At(0).Type(), results.At(0).Type(), true, true
+	}
+	return nil, nil, false, false
+}
+
+// lookupConverter 查找用户注册的 srcType -> dstType 转换函数
+func (e *typeEnv) lookupConverter(srcType, dstType types.Type, mode errMode) (conversion, bool) {
+	c, ok := e.converters[pairKey(srcType, dstType)]
+	if !ok {
+		return conversion{}, false
+	}
+	return e.converterConversion(c, srcType, dstType, mode), true
+}
+
+// converterConversion 返回调用转换函数 c 的方式，srcType、dstType 是 c 的参数和返回值类型
+func (e *typeEnv) converterConversion(c *converter, srcType, dstType types.Type, mode errMode) conversion {
+	name := c.name
+	if q := e.qualifier(c.pkg); q != "" {
+		name = q + "." + name
+	}
+	if c.fallible && mode != errIgnore {
+		return conversion{Func: name, Fallible: true, ErrSep: errSepLeaf}
+	}
+	if c.fallible {
+		// 拷贝函数不返回 error 时，与内置的 strconv、time.Parse 转换一致，忽略错误
+		name = fmt.Sprintf("func(v %s) %s { r, _ := %s(v); return r }",
+			e.typeString(srcType), e.typeString(dstType), name)
+	}
+	return conversion{Func: name}
+}
@@ -0,0 +1,65 @@
+package quickcopy
+
+import (
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+// fieldIgnores 是指令中有意不拷贝的字段
+type fieldIgnores struct {
+	dst map[string]token.Pos // -Password：目标字段没有来源，值是指令中的位置
+	src map[string]token.Pos // _ = Password：源字段不使用
+}
+
+// byPos 按在指令中的位置返回忽略的字段，诊断信息的顺序和声明顺序一致
+func byPos(ignores map[string]token.Pos) []string {
+	names := make([]string, 0, len(ignores))
+	for name := range ignores {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return ignores[names[i]] < ignores[names[j]] })
+	return names
+}
+
+// fieldCoverage 记录两边没有对应关系的字段，用于 --strict 和未使用源字段的检查
+type fieldCoverage struct {
+	unmappedDst []string // 没有来源的目标字段
+	unusedSrc   []string // 没有被任何目标字段使用的源字段
+	badRules    bool     // 有无法生成的表达式规则，错误已经报告
+}
+
+// unmappedFields 返回 mapped 之外的字段，按声明顺序。内嵌结构体的字段按提升后的名字列出，
+// quickcopy:"-" 标签的字段不算在内
+func unmappedFields(dstStruct *types.Struct, mapped map[string]bool, env *typeEnv) []string {
+	var names []string
+	listed := make(map[string]bool)
+	seen := make(map[*types.Struct]bool)
+	var walk func(st *types.Struct)
+	walk = func(st *types.Struct) {
+		if seen[st] {
+			return
+		}
+		seen[st] = true
+		for i := 0; i < st.NumFields(); i++ {
+			field := st.Field(i)
+			if field.Anonymous() {
+				if embedded, _ := embeddedStruct(field); embedded != nil {
+					walk(embedded)
+					continue
+				}
+			}
+			if field.Name() == "_" || !env.isAccessible(field) || mapped[field.Name()] || listed[field.Name()] {
+				continue
+			}
+			if fieldTagAt(st, i, env).skip {
+				continue
+			}
+			// 同名字段只列一次
+			listed[field.Name()] = true
+			names = append(names, field.Name())
+		}
+	}
+	walk(dstStruct)
+	return names
+}
@@ -0,0 +1,186 @@
+package quickcopy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext 是 unified diff 中每处修改前后保留的行数
+const diffContext = 3
+
+// diffMaxEdits 超过这个编辑距离时不再逐行比较，直接输出整个文件的替换
+const diffMaxEdits = 2000
+
+// lineEdit 是一行的比较结果，op 为 ' '、'-' 或 '+'
+type lineEdit struct {
+	op   byte
+	text string
+}
+
+// splitLines 按行分割，每行保留结尾的换行符
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines 使用 Myers 算法计算 a 到 b 的最短编辑序列
+func diffLines(a, b []string) []lineEdit {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+	for d := 0; d <= n+m; d++ {
+		if d > diffMaxEdits {
+			return replaceAll(a, b)
+		}
+		// 第 d 轮只会读取 k ∈ [-d-1, d+1]，只保存这一段
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	return replaceAll(a, b)
+}
+
+// backtrack 根据每一轮的状态倒推出编辑序列，trace[d] 中下标 d+1 对应 k = 0
+func backtrack(a, b []string, trace [][]int) []lineEdit {
+	var edits []lineEdit
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		offset := d + 1
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			edits = append(edits, lineEdit{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			edits = append(edits, lineEdit{'+', b[y-1]})
+			y--
+		} else {
+			edits = append(edits, lineEdit{'-', a[x-1]})
+			x--
+		}
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+func replaceAll(a, b []string) []lineEdit {
+	edits := make([]lineEdit, 0, len(a)+len(b))
+	for _, line := range a {
+		edits = append(edits, lineEdit{'-', line})
+	}
+	for _, line := range b {
+		edits = append(edits, lineEdit{'+', line})
+	}
+	return edits
+}
+
+// unifiedDiff 返回 oldText 到 newText 的 unified diff，内容相同时返回空字符串
+func unifiedDiff(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	edits := diffLines(splitLines(oldText), splitLines(newText))
+
+	// 每个编辑之前在旧文件和新文件中已经经过的行数
+	oldPos := make([]int, len(edits)+1)
+	newPos := make([]int, len(edits)+1)
+	for i, e := range edits {
+		oldPos[i+1], newPos[i+1] = oldPos[i], newPos[i]
+		if e.op != '+' {
+			oldPos[i+1]++
+		}
+		if e.op != '-' {
+			newPos[i+1]++
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for i := 0; i < len(edits); {
+		// 找到下一处修改
+		for i < len(edits) && edits[i].op == ' ' {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		// 相隔不超过 2*diffContext 行的修改合并到同一个 hunk
+		start := max(i-diffContext, 0)
+		end := i
+		for end < len(edits) {
+			if edits[end].op != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(edits) && edits[next].op == ' ' {
+				next++
+			}
+			if next == len(edits) || next-end > 2*diffContext {
+				end = min(end+diffContext, len(edits))
+				break
+			}
+			end = next
+		}
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(oldPos[start], oldPos[end]-oldPos[start]),
+			hunkRange(newPos[start], newPos[end]-newPos[start]))
+		for _, e := range edits[start:end] {
+			out.WriteByte(e.op)
+			out.WriteString(e.text)
+			if !strings.HasSuffix(e.text, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return out.String()
+}
+
+// hunkRange 返回 hunk 头中的行号范围，行号从 1 开始，空范围使用前一行的行号
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,349 @@
+package quickcopy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+const copyDirective = "// :quickcopy"
+
+// 指令的语法，从 // :quickcopy 所在的行开始，到文档注释结束、空的 // 行或者 //go: 这类注释为止：
+//
+//	directive = ":quickcopy" { item } .
+//	item      = option | ignore | rule .
+//	option    = "--" name [ "=" value ] .
+//	ignore    = "-" path | "_" "=" path .
+//	rule      = path "=" expr .
+//	path      = identifier { "." identifier } .
+//
+// 规则、忽略之间用逗号或者换行分隔，选项用空白分隔，可以写在一行的任意位置。
+// expr 是 Go 表达式，括号和字符串字面量中的逗号、空白不分隔；value 中的逗号属于选项本身，如 --strip=Src,DTO
+
+// directive 是解析后的 // :quickcopy 指令
+type directive struct {
+	opts          copyOptions // 沿用到生成的辅助函数的选项
+	joinErrors    bool
+	fieldMappings []mappingRule     // 字段映射规则
+	ignores       fieldIgnores      // -Password、_ = Password：有意不拷贝的字段
+	strict        bool              // --strict：所有目标字段都必须有来源
+	strictSource  bool              // --strict-source：所有源字段都必须被使用
+	problems      []directiveError  // 无法解析的映射规则、选项，生成时报告为错误
+	nilDefaults   map[string]string // 源指针为 nil 时目标字段的默认值
+}
+
+// mappingRule 是一条 Dst = Src 映射规则，Src 也可以是表达式或者常量
+type mappingRule struct {
+	dst, src string
+	pos      token.Pos // 规则的位置，和这条规则有关的诊断信息指向这里
+}
+
+// directiveOption 是一个 --name 或者 --name=value 选项
+type directiveOption struct {
+	name, value string
+	pos         token.Pos
+}
+
+// directiveError 是指令中无法解析的部分，pos 指向出错的词
+type directiveError struct {
+	pos token.Pos
+	msg string
+}
+
+// optionTakesValue 是指令支持的选项，true 表示需要 =value
+var optionTakesValue = map[string]bool{
+	"allow-narrow":    false,
+	"ignore-case":     false,
+	"single-to-slice": false,
+	"join-errors":     false,
+	"strict":          false,
+	"strict-source":   false,
+	"flatten":         false,
+	"match":           true,
+	"strip":           true,
+	"match-tag":       true,
+	"default":         true,
+}
+
+// directiveWord 是指令中用空白分隔的一个词
+type directiveWord struct {
+	text string
+	pos  token.Pos
+}
+
+// directiveParser 逐行扫描指令，把词组合成选项、规则和忽略的字段
+type directiveParser struct {
+	options []directiveOption
+	rules   []mappingRule
+	ignores fieldIgnores
+	errs    []directiveError
+
+	item []directiveWord // 当前规则中已经读到的词
+}
+
+// parseDirective 从文档注释中找到 // :quickcopy 指令并解析，
+// defaults 是命令行设置的默认选项，指令中的选项在此基础上追加
+func parseDirective(doc *ast.CommentGroup, defaults Options) (*directive, bool) {
+	p := &directiveParser{
+		ignores: fieldIgnores{dst: make(map[string]token.Pos), src: make(map[string]token.Pos)},
+	}
+	if !p.parse(doc) {
+		return nil, false
+	}
+
+	d := &directive{
+		opts: copyOptions{
+			match: fieldMatcher{
+				ignoreCase: defaults.IgnoreCase,
+				tags:       defaults.MatchTags,
+				flatten:    defaults.Flatten,
+			},
+			allowNarrow:   defaults.AllowNarrow,
+			singleToSlice: defaults.SingleToSlice,
+		},
+		joinErrors:    defaults.JoinErrors,
+		fieldMappings: p.rules,
+		ignores:       p.ignores,
+		strict:        defaults.Strict,
+		strictSource:  defaults.StrictSource,
+		nilDefaults:   make(map[string]string),
+	}
+	match, strip := defaults.Match, defaults.Strip
+	matchPos := token.NoPos
+	for _, opt := range p.options {
+		switch opt.name {
+		case "allow-narrow":
+			d.opts.allowNarrow = true
+		case "ignore-case":
+			d.opts.match.ignoreCase = true
+		case "single-to-slice":
+			d.opts.singleToSlice = true
+		case "join-errors":
+			d.joinErrors = true
+		case "strict":
+			d.strict = true
+		case "strict-source":
+			d.strictSource = true
+		case "flatten":
+			d.opts.match.flatten = true
+		case "match":
+			match, matchPos = opt.value, opt.pos
+		case "strip":
+			strip = splitList(opt.value)
+		case "match-tag":
+			d.opts.match.tags = splitList(opt.value)
+		case "default":
+			field, def, ok := strings.Cut(opt.value, ":")
+			if !ok || field == "" || def == "" {
+				p.errorf(opt.pos, "invalid --default=%s, want Field:value", opt.value)
+				continue
+			}
+			d.nilDefaults[field] = def
+		}
+	}
+	var err error
+	d.opts.match.normalized, d.opts.match.strip, err = matchOptions(match, strip)
+	if err != nil {
+		p.errorf(matchPos, "%v", err)
+	}
+	d.problems = p.errs
+	return d, true
+}
+
+// parse 扫描文档注释中的指令块，没有指令时返回 false
+func (p *directiveParser) parse(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	found := false
+	for _, c := range doc.List {
+		if !found {
+			if !isDirective(c.Text, copyDirective) {
+				continue
+			}
+			found = true
+		}
+
+		text, offset := c.Text, len(copyDirective)
+		if !isDirective(text, copyDirective) {
+			// 指令后面的注释行是指令的一部分，/* */ 注释、空的 // 行、//go: 这类注释结束指令
+			if !strings.HasPrefix(text, "// ") && !strings.HasPrefix(text, "//\t") || strings.TrimSpace(text[2:]) == "" {
+				return true
+			}
+			offset = len("//")
+		}
+		p.scanLine(text[offset:], c.Slash+token.Pos(offset))
+	}
+	return found
+}
+
+// scanLine 扫描一行指令，base 是 line 第一个字符的位置。换行和逗号一样分隔规则
+func (p *directiveParser) scanLine(line string, base token.Pos) {
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case ' ', '\t':
+			i++
+			continue
+		case ',':
+			p.endItem()
+			i++
+			continue
+		}
+
+		option := strings.HasPrefix(line[i:], "--")
+		end, err := scanWord(line, i, option)
+		if err != nil {
+			// 丢掉这一行中无法解析的规则
+			p.errorf(base+token.Pos(end), "%v", err)
+			p.item = p.item[:0]
+			return
+		}
+		word := directiveWord{text: line[i:end], pos: base + token.Pos(i)}
+		i = end
+		if !option {
+			p.item = append(p.item, word)
+			continue
+		}
+		// 选项后面紧跟的逗号结束当前规则
+		if text, ok := strings.CutSuffix(word.text, ","); ok {
+			word.text = text
+			p.endItem()
+		}
+		p.option(word)
+	}
+	p.endItem()
+}
+
+// scanWord 返回从 start 开始的词的结束位置。字符串字面量、括号中的空白和逗号属于这个词；
+// 选项中的逗号属于选项的值。字符串没有结束时返回引号的位置和错误
+func scanWord(line string, start int, option bool) (int, error) {
+	depth := 0
+	for i := start; i < len(line); i++ {
+		switch c := line[i]; c {
+		case '"', '\'', '`':
+			j := i + 1
+			for j < len(line) && line[j] != c {
+				if line[j] == '\\' && c != '`' {
+					j++
+				}
+				j++
+			}
+			if j >= len(line) {
+				return i, fmt.Errorf("unterminated %s", quoteName(c))
+			}
+			i = j
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ' ', '\t':
+			if depth <= 0 {
+				return i, nil
+			}
+		case ',':
+			if depth <= 0 && !option {
+				return i, nil
+			}
+		}
+	}
+	return len(line), nil
+}
+
+func quoteName(c byte) string {
+	switch c {
+	case '\'':
+		return "rune literal"
+	case '`':
+		return "raw string literal"
+	}
+	return "string literal"
+}
+
+// option 记录一个 --name=value 选项，检查选项名和值
+func (p *directiveParser) option(w directiveWord) {
+	name, value, hasValue := strings.Cut(strings.TrimPrefix(w.text, "--"), "=")
+	takesValue, ok := optionTakesValue[name]
+	switch {
+	case !ok:
+		p.errorf(w.pos, "unknown option --%s", name)
+	case takesValue && (!hasValue || value == ""):
+		p.errorf(w.pos, "option --%s requires a value", name)
+	case !takesValue && hasValue:
+		p.errorf(w.pos, "option --%s does not take a value", name)
+	default:
+		p.options = append(p.options, directiveOption{name: name, value: value, pos: w.pos})
+	}
+}
+
+// endItem 把当前读到的词解析成一条规则或者忽略的字段
+func (p *directiveParser) endItem() {
+	if len(p.item) == 0 {
+		return
+	}
+	words := make([]string, len(p.item))
+	for i, w := range p.item {
+		words[i] = w.text
+	}
+	text := strings.Join(words, " ")
+	pos := p.item[0].pos
+	p.item = p.item[:0]
+
+	// -Password：目标字段有意不拷贝
+	if name, ok := strings.CutPrefix(text, "-"); ok {
+		name = strings.TrimSpace(name)
+		if !isFieldPath(name) {
+			p.errorf(pos, "invalid ignore %q, want -Field", text)
+			return
+		}
+		p.ignore(p.ignores.dst, name, pos)
+		return
+	}
+
+	dst, src, ok := strings.Cut(text, "=")
+	dst, src = strings.TrimSpace(dst), strings.TrimSpace(src)
+	switch {
+	case !ok || src == "" || strings.HasPrefix(src, "="):
+		p.errorf(pos, "invalid mapping rule %q, want Field = Source", text)
+	case dst == "_":
+		// _ = Password：源字段有意不使用
+		if !isFieldPath(src) {
+			p.errorf(pos, "invalid ignore %q, want _ = Field", text)
+			return
+		}
+		p.ignore(p.ignores.src, src, pos)
+	case !isFieldPath(dst):
+		p.errorf(pos, "invalid mapping rule %q: %s is not a field", text, dst)
+	default:
+		for _, r := range p.rules {
+			if r.dst == dst {
+				p.errorf(pos, "duplicate mapping rule for %s", dst)
+				return
+			}
+		}
+		p.rules = append(p.rules, mappingRule{dst: dst, src: src, pos: pos})
+	}
+}
+
+func (p *directiveParser) ignore(ignores map[string]token.Pos, name string, pos token.Pos) {
+	if _, ok := ignores[name]; ok {
+		p.errorf(pos, "duplicate ignore for %s", name)
+		return
+	}
+	ignores[name] = pos
+}
+
+func (p *directiveParser) errorf(pos token.Pos, format string, args ...any) {
+	p.errs = append(p.errs, directiveError{pos: pos, msg: fmt.Sprintf(format, args...)})
+}
+
+// splitList 分割逗号分隔的列表，去掉空白和空的部分
+func splitList(value string) []string {
+	var list []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
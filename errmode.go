@@ -0,0 +1,149 @@
+package quickcopy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// errMode 决定生成的代码如何处理可能失败的转换（strconv.Atoi、time.Parse 等）
+type errMode int
+
+const (
+	errIgnore errMode = iota // 拷贝函数没有返回值，忽略转换错误
+	errReturn                // 拷贝函数返回 error，遇到第一个错误立即返回
+	errJoin                  // 拷贝函数返回 error，收集所有错误后通过 errors.Join 返回
+)
+
+// 转换失败时错误路径的分隔符
+const (
+	errSepLeaf   = ": " // 普通转换函数返回的原始错误
+	errSepStruct = "."  // 结构体拷贝函数返回的错误以字段名开头
+	errSepNested = ""   // 切片、数组、map 拷贝函数返回的错误以 [i] 开头
+)
+
+// suffix 返回可能失败的辅助函数名后缀，用于区分不同模式下生成的同名函数
+func (m errMode) suffix() string {
+	switch m {
+	case errReturn:
+		return "Err"
+	case errJoin:
+		return "Errs"
+	}
+	return ""
+}
+
+// errDecl 返回函数开头声明 err 以及 errs 的语句
+func (m errMode) errDecl() string {
+	if m == errJoin {
+		return "var err error\nvar errs []error\n"
+	}
+	return "var err error\n"
+}
+
+// onErr 返回转换失败时执行的语句：给 err 加上路径后直接返回，或者追加到 errs 中。
+// zero 是直接返回时 error 前面的返回值（如 "nil, "），path 是 fmt.Errorf 的格式串
+// （如 "Name"、"[%d]"），args 是格式串对应的参数，path 为空时不加路径
+func (m errMode) onErr(zero, path string, conv conversion, env *typeEnv, args ...string) string {
+	if m == errJoin {
+		if path == "" {
+			return "errs = append(errs, err)"
+		}
+		prefix := strconv.Quote(path + conv.ErrSep)
+		if len(args) > 0 {
+			prefix = fmt.Sprintf("fmt.Sprintf(%s, %s)", prefix, strings.Join(args, ", "))
+		}
+		return fmt.Sprintf("errs = %s(errs, %s, err)", generateAppendErrorsFunc(env), prefix)
+	}
+
+	if path == "" {
+		return fmt.Sprintf("return %serr", zero)
+	}
+	env.addImport("fmt")
+	return fmt.Sprintf("return %sfmt.Errorf(%q, %s)", zero, path+conv.ErrSep+"%w", strings.Join(append(args, "err"), ", "))
+}
+
+const appendErrorsFuncName = "appendCopyErrors"
+
+// generateAppendErrorsFunc 生成收集错误的辅助函数。
+// 辅助函数返回的 errors.Join 会被拆开，给每个错误分别加上路径
+func generateAppendErrorsFunc(env *typeEnv) string {
+	env = env.helperEnv()
+	env.addImport("fmt")
+	if env.scope.hasHelper(appendErrorsFuncName) {
+		return appendErrorsFuncName
+	}
+
+	code := fmt.Sprintf(`package main
+// %s 是自动生成的函数，给 err 中的每个错误加上路径前缀后追加到 errs
+func %s(errs []error, prefix string, err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			errs = %s(errs, prefix, e)
+		}
+		return errs
+	}
+	return append(errs, fmt.Errorf("%%s%%w", prefix, err))
+}`, appendErrorsFuncName, appendErrorsFuncName, appendErrorsFuncName)
+
+	file, err := parser.ParseFile(token.NewFileSet(), "", code, parser.ParseComments)
+	if err != nil {
+		env.errorf(token.NoPos, "parse generated function: %v", err)
+		return appendErrorsFuncName
+	}
+	addGeneratedFunction(appendErrorsFuncName, file.Decls[0].(*ast.FuncDecl), env)
+	return appendErrorsFuncName
+}
+
+// returnErr 返回函数结尾的 error 返回值
+func (m errMode) returnErr(env *typeEnv) string {
+	if m == errJoin {
+		env.addImport("errors")
+		return "errors.Join(errs...)"
+	}
+	return "nil"
+}
+
+// errorMode 根据拷贝函数的返回值决定错误处理方式，返回值只能为空或者是 error
+func errorMode(sig *types.Signature, joinErrors bool) (errMode, bool) {
+	results := sig.Results()
+	switch {
+	case results.Len() == 0:
+		return errIgnore, true
+	case results.Len() == 1 && types.Identical(results.At(0).Type(), types.Universe.Lookup("error").Type()):
+		if joinErrors {
+			return errJoin, true
+		}
+		return errReturn, true
+	}
+	return errIgnore, false
+}
+
+// generateFallibleConversion 生成可能失败的转换语句，调用者负责检查 err
+func generateFallibleConversion(srcVar, dstVar string, conv conversion) string {
+	if conv.IsStruct {
+		return fmt.Sprintf("err = %s(&%s, &%s)", conv.Func, dstVar, srcVar)
+	}
+	return fmt.Sprintf("%s, err = %s(%s)", dstVar, conv.Func, srcVar)
+}
+
+// handleFallibleTypeConversion 是 handleSpecialTypeConversion 中可能失败的转换对应的 func(A) (B, error) 版本
+func handleFallibleTypeConversion(srcType, dstType string) (code string, importPath string) {
+	switch {
+	case srcType == "string" && dstType == "float64":
+		return "func(s string) (float64, error) { return strconv.ParseFloat(s, 64) }", "strconv"
+	case srcType == "string" && dstType == "int":
+		return "strconv.Atoi", "strconv"
+	case srcType == "string" && dstType == "int64":
+		return "func(s string) (int64, error) { return strconv.ParseInt(s, 10, 64) }", "strconv"
+	case srcType == "string" && dstType == "time.Time":
+		return "func(s string) (time.Time, error) { return time.Parse(time.RFC3339, s) }", "time"
+	case srcType == "string" && dstType == "uuid.UUID":
+		return "uuid.Parse", "github.com/google/uuid"
+	}
+	return "", ""
+}
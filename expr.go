@@ -0,0 +1,104 @@
+package quickcopy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// exprScope 是对表达式规则做类型检查需要的信息
+type exprScope struct {
+	dstVar, srcVar string // 拷贝函数的参数名，表达式通过 srcVar 读取源字段
+}
+
+// errNotFieldPath 表示规则右边不是字段路径，按表达式处理
+var errNotFieldPath = errors.New("not a field path")
+
+// isFieldPath 判断规则右边是不是 Location.City.Name 形式的字段路径
+func isFieldPath(s string) bool {
+	expr, err := parser.ParseExpr(s)
+	if err != nil {
+		return false
+	}
+	for {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			return true
+		case *ast.SelectorExpr:
+			expr = e.X
+		default:
+			return false
+		}
+	}
+}
+
+// checkRuleExpr 把 dst.Path = expr 放到函数字面量中，在规则 pos 所在文件的作用域中做类型检查，
+// 可以使用文件导入的包，类型不匹配、常量溢出、拼错的字段都在生成时报告。
+// 返回写到拷贝函数中的表达式，以及表达式读取的源字段
+func checkRuleExpr(dstPath, expr string, dstType, srcType types.Type, pos token.Pos, scope exprScope, env *typeEnv) (string, []string, error) {
+	code := fmt.Sprintf("func(%s *%s, %s *%s) {\n%s.%s = %s\n}",
+		scope.dstVar, env.typeString(dstType), scope.srcVar, env.typeString(srcType), scope.dstVar, dstPath, expr)
+	fset := token.NewFileSet()
+	node, err := parser.ParseExprFrom(fset, "", code, 0)
+	lit, ok := node.(*ast.FuncLit)
+	if err != nil || !ok || len(lit.Body.List) != 1 {
+		return "", nil, fmt.Errorf("invalid expression %s", expr)
+	}
+
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+	if err := types.CheckExpr(env.pkg.Fset, env.pkg.Types, pos, lit, info); err != nil {
+		var terr types.Error
+		if errors.As(err, &terr) {
+			return "", nil, errors.New(terr.Msg)
+		}
+		return "", nil, err
+	}
+
+	// 表达式按规则所在文件的导入写成，生成到单独的文件时那里没有这些别名和点导入，
+	// 用到的包按目标文件的写法重新限定
+	srcParam := info.Defs[lit.Type.Params.List[1].Names[0]]
+	var used []string
+	rhs := astutil.Apply(lit.Body.List[0].(*ast.AssignStmt).Rhs[0], func(c *astutil.Cursor) bool {
+		switch n := c.Node().(type) {
+		case *ast.SelectorExpr:
+			if x, ok := n.X.(*ast.Ident); ok && info.Uses[x] == srcParam {
+				used = append(used, n.Sel.Name)
+			}
+		case *ast.Ident:
+			if c.Name() == "Sel" {
+				return true
+			}
+			switch obj := info.Uses[n].(type) {
+			case *types.PkgName:
+				if name := env.qualifier(obj.Imported()); name != "" {
+					n.Name = name
+				}
+			case nil:
+			default:
+				// 点导入的包中的函数、常量、类型等
+				if p := obj.Pkg(); p != nil && p != env.pkg.Types && obj.Parent() == p.Scope() {
+					if name := env.qualifier(p); name != "" {
+						c.Replace(&ast.SelectorExpr{X: ast.NewIdent(name), Sel: ast.NewIdent(n.Name)})
+					}
+				}
+			}
+		}
+		return true
+	}, nil)
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, rhs); err != nil {
+		return "", nil, err
+	}
+	return buf.String(), used, nil
+}
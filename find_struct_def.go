@@ -1,197 +1,211 @@
 package quickcopy
 
 import (
-	"go/ast"
-	"go/token"
-	"log"
-	"path/filepath"
+	"fmt"
+	"go/types"
 	"strings"
-
-	"golang.org/x/tools/go/packages"
 )
 
-// 新增缓存：包路径 -> 包语法树
-// var pkgCache = make(map[string][]*ast.File)
-var pkgCache Map[string, []*ast.File]
-
-// findStructDef 优化后的实现
-func findStructDef(typeName string, file *ast.File, path string) *ast.StructType {
-	if file == nil {
-		log.Printf("File is nil, cannot find struct definition for %s", typeName)
-		return nil
-	}
-
-	// 1. 尝试从当前文件查找
-	if structType := findInCurrentFile(typeName, file); structType != nil {
-		return structType
-	}
+// findStructDef 返回类型对应的结构体定义，具名类型、别名和跨包类型都取其底层类型
+func findStructDef(t types.Type) *types.Struct {
+	st, _ := t.Underlying().(*types.Struct)
+	return st
+}
 
-	// 2. 解析包路径和类型名（处理形如 pkg.Type 的类型）
-	pkgPath, typeName := parsePkgType(typeName)
-	if pkgPath != "" {
-		// 处理外部包类型
-		return findInImportedPackage(pkgPath, typeName, file)
+// embeddedStruct 返回内嵌字段的结构体定义。内嵌的是结构体指针（*Base）时取指向的结构体，elem 为指向的类型
+func embeddedStruct(field *types.Var) (st *types.Struct, elem types.Type) {
+	t := field.Type()
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		return findStructDef(ptr.Elem()), ptr.Elem()
 	}
-
-	// 3. 尝试从同一包的其他文件查找
-	return findInCurrentPackage(typeName, file, path)
+	return findStructDef(t), nil
 }
 
-// 1. 在当前文件查找
-func findInCurrentFile(typeName string, file *ast.File) *ast.StructType {
-	for _, decl := range file.Decls {
-		genDecl, ok := decl.(*ast.GenDecl)
-		if !ok || genDecl.Tok != token.TYPE {
-			continue
-		}
-
-		for _, spec := range genDecl.Specs {
-			typeSpec, ok := spec.(*ast.TypeSpec)
-			if !ok || typeSpec.Name.Name != typeName {
+// embeddedPtrs 返回提升字段 target 所在的内嵌路径上的结构体指针，如内嵌 *Base 时为 Base。
+// 通过提升的字段读取前要判断这些指针是否为 nil，写入前要分配
+func embeddedPtrs(st *types.Struct, target *types.Var) ([]string, []types.Type) {
+	type level struct {
+		st    *types.Struct
+		path  string
+		ptrs  []string
+		elems []types.Type
+	}
+	current := []level{{st: st}}
+	seen := make(map[*types.Struct]bool)
+	for len(current) > 0 {
+		var next []level
+		for _, l := range current {
+			if seen[l.st] {
 				continue
 			}
+			seen[l.st] = true
 
-			if structType, ok := typeSpec.Type.(*ast.StructType); ok {
-				log.Printf("Found struct in current file: %s", typeName)
-				return structType
+			for i := 0; i < l.st.NumFields(); i++ {
+				field := l.st.Field(i)
+				if field == target {
+					return l.ptrs, l.elems
+				}
+				if !field.Anonymous() {
+					continue
+				}
+				embedded, elem := embeddedStruct(field)
+				if embedded == nil {
+					continue
+				}
+				sub := level{st: embedded, path: field.Name(), ptrs: l.ptrs, elems: l.elems}
+				if l.path != "" {
+					sub.path = l.path + "." + field.Name()
+				}
+				if elem != nil {
+					sub.ptrs = append(append([]string(nil), l.ptrs...), sub.path)
+					sub.elems = append(append([]types.Type(nil), l.elems...), elem)
+				}
+				next = append(next, sub)
 			}
 		}
+		current = next
 	}
-	return nil
+	return nil, nil
 }
 
-// 2. 在导入的包中查找
-func findInImportedPackage(pkgPath, typeName string, file *ast.File) *ast.StructType {
-	// 从文件的 imports 中查找完整的包路径
-	var fullPkgPath string
-	for _, imp := range file.Imports {
-		importedPath := strings.Trim(imp.Path.Value, `"`)
-		// 匹配别名或路径最后部分
-		if imp.Name != nil && imp.Name.Name == pkgPath {
-			fullPkgPath = importedPath
-			break
-		}
-		if filepath.Base(importedPath) == pkgPath {
-			fullPkgPath = importedPath
-			break
-		}
-	}
-
-	if fullPkgPath == "" {
-		log.Printf("Package not imported: %s", pkgPath)
-		return nil
-	}
-
-	return findStructDefInPackage(fullPkgPath, typeName)
+// findFieldByName 在结构体中查找字段，支持内嵌结构体
+func findFieldByName(structType *types.Struct, fieldName string, env *typeEnv) (*types.Var, fieldTag) {
+	return lookupField(structType, func(field *types.Var, _ fieldTag) bool { return field.Name() == fieldName }, env)
 }
 
-// 3. 在同一包的其他文件中查找
-func findInCurrentPackage(typeName string, file *ast.File, path string) *ast.StructType {
-
-	pkgPath := filepath.Dir(path)
+// fieldPath 是映射规则中 Address.City 形式的字段路径
+type fieldPath struct {
+	field *types.Var // 最后一级字段
+	tag   fieldTag
+	top   string // 第一个不是内嵌结构体的字段，检查未映射字段时使用，Meta.Nick 中为 Nick
 
-	// 检查缓存
-
-	files, ok := pkgCache.Load(pkgPath)
+	ptrs     []string     // 路径上的中间指针，如 Location、Location.City
+	ptrElems []types.Type // 中间指针指向的类型，目标一侧为 nil 时分配
+}
 
-	if !ok {
-		// 未缓存，加载包信息
-		cfg := &packages.Config{
-			Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax,
-			Dir:  pkgPath,
+// resolveFieldPath 逐级解析字段路径，每一级都可以是内嵌结构体提升的字段，中间一级可以是结构体指针
+func resolveFieldPath(st *types.Struct, path string, env *typeEnv) (fieldPath, error) {
+	segments := strings.Split(path, ".")
+	var fp fieldPath
+	for i, name := range segments {
+		field, tag := findFieldByName(st, name, env)
+		if field == nil {
+			field, tag = findEmbeddedField(st, name, env)
+		}
+		if field == nil {
+			if i == 0 {
+				return fp, fmt.Errorf("field %s not found", name)
+			}
+			return fp, fmt.Errorf("%s has no field %s", strings.Join(segments[:i], "."), name)
 		}
-		pkgs, err := packages.Load(cfg, ".")
-		if err != nil || len(pkgs) == 0 {
-			log.Printf("Failed to load current package: %v", err)
-			return nil
+		// 内嵌结构体指针提升的字段，路径上的这些指针也要判断 nil 或者分配
+		ptrs, elems := embeddedPtrs(st, field)
+		for j, p := range ptrs {
+			if i > 0 {
+				p = strings.Join(segments[:i], ".") + "." + p
+			}
+			fp.ptrs = append(fp.ptrs, p)
+			fp.ptrElems = append(fp.ptrElems, elems[j])
 		}
-
-		// 提取语法树并缓存
-		var syntax []*ast.File
-		for _, pkg := range pkgs {
-			syntax = append(syntax, pkg.Syntax...)
+		if fp.top == "" && (!field.Anonymous() || i == len(segments)-1) {
+			fp.top = field.Name()
+		}
+		if i == len(segments)-1 {
+			fp.field, fp.tag = field, tag
+			break
 		}
 
-		pkgCache.Store(pkgPath, syntax)
-		files = syntax
+		prefix := strings.Join(segments[:i+1], ".")
+		t := field.Type()
+		if ptr, ok := t.Underlying().(*types.Pointer); ok {
+			fp.ptrs = append(fp.ptrs, prefix)
+			fp.ptrElems = append(fp.ptrElems, ptr.Elem())
+			t = ptr.Elem()
+		}
+		if st = findStructDef(t); st == nil {
+			return fp, fmt.Errorf("%s is not a struct", prefix)
+		}
 	}
+	return fp, nil
+}
 
-	// 遍历所有文件查找结构体
-	for _, f := range files {
-		if structType := findInCurrentFile(typeName, f); structType != nil {
-			log.Printf("Found struct in package %s: %s", pkgPath, typeName)
-			return structType
+// findEmbeddedField 按类型名查找内嵌字段本身，如 Meta.Nick 中的 Meta
+func findEmbeddedField(st *types.Struct, name string, env *typeEnv) (*types.Var, fieldTag) {
+	for i := 0; i < st.NumFields(); i++ {
+		if field := st.Field(i); field.Anonymous() && field.Name() == name && env.isAccessible(field) {
+			return field, fieldTagAt(st, i, env)
 		}
 	}
-
-	return nil
+	return nil, fieldTag{}
 }
 
-// findStructDefInPackage 在包中查找结构体定义
-func findStructDefInPackage(pkgPath, structName string) (structType *ast.StructType) {
-	// 配置 packages.Config
-	cfg := &packages.Config{
-		Mode: packages.NeedName |
-			packages.NeedSyntax |
-			packages.NeedTypes |
-			packages.NeedDeps,
-	}
+// topField 返回字段路径的第一级
+func topField(path string) string {
+	name, _, _ := strings.Cut(path, ".")
+	return name
+}
 
-	// 加载包
-	pkgs, err := packages.Load(cfg, pkgPath)
-	if err != nil {
-		log.Printf("Failed to load package %s: %v", pkgPath, err)
-		return nil
+// findSourceField 查找和目标字段对应的源字段，两边都按 quickcopy 标签中的 name 匹配，
+// 没有 name 时使用字段名，标签指定的名字优先。标签为 "-" 的源字段不参与匹配
+func findSourceField(srcStruct *types.Struct, key string, match fieldMatcher, env *typeEnv) (*types.Var, fieldTag) {
+	equal := func(name string) bool { return match.equal(name, key) }
+	if field, tag := lookupField(srcStruct, func(_ *types.Var, tag fieldTag) bool {
+		return !tag.skip && tag.name != "" && equal(tag.name)
+	}, env); field != nil {
+		return field, tag
 	}
+	return lookupField(srcStruct, func(field *types.Var, tag fieldTag) bool {
+		return !tag.skip && tag.name == "" && equal(field.Name())
+	}, env)
+}
 
-	if len(pkgs) == 0 {
-		log.Printf("No packages found for %s", pkgPath)
-		return nil
+// findSourceFieldByTags 按 --match-tag 指定的标签查找源字段，依次尝试每个标签，
+// 目标字段没有这个标签时跳过
+func findSourceFieldByTags(srcStruct *types.Struct, dstTag string, keys []string, env *typeEnv) (*types.Var, fieldTag) {
+	for _, key := range keys {
+		name := tagValueName(dstTag, key)
+		if name == "" {
+			continue
+		}
+		if field, tag := lookupField(srcStruct, func(_ *types.Var, tag fieldTag) bool {
+			return !tag.skip && tagValueName(tag.raw, key) == name
+		}, env); field != nil {
+			return field, tag
+		}
 	}
+	return nil, fieldTag{}
+}
 
-	// 遍历包中的文件
-	for _, pkg := range pkgs {
-		log.Printf("Inspecting package: %s, syntax: %d\n", pkg.PkgPath, len(pkg.Syntax))
-		for _, file := range pkg.Syntax {
-			// 遍历 AST
-			ast.Inspect(file, func(n ast.Node) bool {
-				// 查找类型声明
-				ts, ok := n.(*ast.TypeSpec)
-				if !ok {
-					return true
+// lookupField 按照 Go 字段提升的规则逐层查找：外层字段优先于内嵌结构体中的字段
+func lookupField(structType *types.Struct, match func(field *types.Var, tag fieldTag) bool, env *typeEnv) (*types.Var, fieldTag) {
+	current := []*types.Struct{structType}
+	seen := make(map[*types.Struct]bool)
+	for len(current) > 0 {
+		var next []*types.Struct
+		for _, st := range current {
+			if seen[st] {
+				continue
+			}
+			seen[st] = true
+
+			for i := 0; i < st.NumFields(); i++ {
+				field := st.Field(i)
+				// 内嵌结构体（包括结构体指针）放到下一层查找
+				if field.Anonymous() {
+					if embedded, _ := embeddedStruct(field); embedded != nil {
+						next = append(next, embedded)
+					}
+					continue
 				}
-
-				log.Printf("Found type: %s", ts.Name.Name)
-				if ts.Name.Name != structName {
-					return true // 继续遍历
+				if !env.isAccessible(field) {
+					continue
 				}
-
-				// 找到目标结构体
-				log.Printf("Found type: %s, struceName:%s\n", ts.Name.Name, structName)
-				if structType, ok = ts.Type.(*ast.StructType); ok {
-					log.Printf("Type %s is a struct with fields:", ts.Name.Name)
-					for _, field := range structType.Fields.List {
-						for _, fieldName := range field.Names {
-							log.Printf("  - %s", fieldName.Name)
-						}
-					}
-					return false // 退出遍历
+				if tag := fieldTagAt(st, i, env); match(field, tag) {
+					return field, tag
 				}
-				return true
-			})
+			}
 		}
+		current = next
 	}
-
-	if structType == nil {
-		log.Printf("Struct definition not found: %s in package %s", structName, pkgPath)
-	}
-	return structType
-}
-
-func parsePkgType(typeStr string) (pkgPath, typeName string) {
-	parts := strings.Split(typeStr, ".")
-	if len(parts) > 1 {
-		return strings.Join(parts[:len(parts)-1], "."), parts[len(parts)-1]
-	}
-	return "", typeStr
+	return nil, fieldTag{}
 }
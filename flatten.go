@@ -0,0 +1,164 @@
+package quickcopy
+
+import (
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// flatField 是嵌套结构体中的字段，name 是路径上各级字段名拼接后的名字，
+// 如 Address.City 对应 AddressCity，--flatten 按它和另一边的字段匹配
+type flatField struct {
+	path  string // 如 Address.City，内嵌结构体的字段按提升后的名字
+	name  string // 如 AddressCity，有 quickcopy 标签时使用标签中的名字
+	field *types.Var
+	tag   fieldTag
+
+	ptrs     []string     // 路径上的中间指针
+	ptrElems []types.Type // 中间指针指向的类型
+}
+
+// nestedFields 列出 parent 下面各级嵌套结构体（包括结构体指针）的字段，按声明顺序，
+// 外层字段在内层字段之前。parent 为空时从 st 开始，只列出第二级及更深的字段
+func nestedFields(st *types.Struct, parent flatField, env *typeEnv) []flatField {
+	var out []flatField
+	stack := make(map[*types.Struct]bool)
+	var walk func(st *types.Struct, parent flatField)
+	walk = func(st *types.Struct, parent flatField) {
+		// 递归的类型（如链表）只展开一次
+		if stack[st] {
+			return
+		}
+		stack[st] = true
+		defer delete(stack, st)
+
+		for i := 0; i < st.NumFields(); i++ {
+			field := st.Field(i)
+			if field.Anonymous() {
+				if embedded, elem := embeddedStruct(field); embedded != nil {
+					// 内嵌结构体指针提升的字段，路径上的指针按内嵌字段名判断
+					sub := parent
+					if elem != nil {
+						path := field.Name()
+						if parent.path != "" {
+							path = parent.path + "." + path
+						}
+						sub.ptrs = append(append([]string(nil), parent.ptrs...), path)
+						sub.ptrElems = append(append([]types.Type(nil), parent.ptrElems...), elem)
+					}
+					walk(embedded, sub)
+					continue
+				}
+			}
+			if field.Name() == "_" || !env.isAccessible(field) {
+				continue
+			}
+			tag := fieldTagAt(st, i, env)
+			if tag.skip {
+				continue
+			}
+
+			f := flatField{
+				path:     field.Name(),
+				name:     parent.name + tag.key(field),
+				field:    field,
+				tag:      tag,
+				ptrs:     parent.ptrs,
+				ptrElems: parent.ptrElems,
+			}
+			if parent.path != "" {
+				f.path = parent.path + "." + field.Name()
+				out = append(out, f)
+			}
+
+			t := field.Type()
+			if ptr, ok := t.Underlying().(*types.Pointer); ok {
+				f.ptrs = append(append([]string(nil), f.ptrs...), f.path)
+				f.ptrElems = append(append([]types.Type(nil), f.ptrElems...), ptr.Elem())
+				t = ptr.Elem()
+			}
+			if nested := findStructDef(t); nested != nil {
+				walk(nested, f)
+			}
+		}
+	}
+	walk(st, parent)
+	return out
+}
+
+// findFlattenedSource 为目标字段 key 查找源结构体中拼接后同名的嵌套字段，
+// 如 AddressCity 对应 src.Address.City。有多个候选时给出警告，不拷贝
+func findFlattenedSource(srcStruct *types.Struct, dstPath, key string, match fieldMatcher, env *typeEnv) (flatField, bool) {
+	var candidates []flatField
+	for _, f := range nestedFields(srcStruct, flatField{}, env) {
+		if match.equal(f.name, key) {
+			candidates = append(candidates, f)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return flatField{}, false
+	case 1:
+		return candidates[0], true
+	}
+	paths := make([]string, len(candidates))
+	for i, c := range candidates {
+		paths[i] = c.path
+	}
+	env.warnf(token.NoPos, "skipping field %s: ambiguous flattened source: %s", dstPath, strings.Join(paths, ", "))
+	return flatField{}, false
+}
+
+// unflattenField 把源结构体中拼接后同名的字段拷贝到目标字段下面的嵌套字段，
+// 如 src.AddressCity 拷贝到 dst.Address.City。allocs 是目标字段所在的内嵌结构体指针。返回是否有字段被拷贝
+func unflattenField(field *types.Var, tag fieldTag, srcStruct *types.Struct, allocs []PathAlloc, opts copyOptions, mode errMode, nilDefaults map[string]string, fields *[]FieldMapping, env *typeEnv) bool {
+	parent := flatField{path: field.Name(), name: tag.key(field)}
+	t := field.Type()
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		parent.ptrs = []string{field.Name()}
+		parent.ptrElems = []types.Type{ptr.Elem()}
+		t = ptr.Elem()
+	}
+	dstStruct := findStructDef(t)
+	if dstStruct == nil {
+		return false
+	}
+
+	var copied []string
+	for _, dst := range nestedFields(dstStruct, parent, env) {
+		// 上一级已经整体拷贝
+		if hasPathPrefix(dst.path, copied) {
+			continue
+		}
+		srcField, srcTag := findSourceField(srcStruct, dst.name, opts.match, env)
+		if srcField == nil {
+			continue
+		}
+		conv, ok := fieldConversion(srcField, dst.field, srcTag, dst.tag, nilDefaults[dst.path], opts, mode, env)
+		if !ok {
+			env.warnf(token.NoPos, "skipping field %s: cannot convert %s to %s",
+				dst.path, env.typeString(srcField.Type()), env.typeString(dst.field.Type()))
+			continue
+		}
+
+		m := newFieldMapping(srcField.Name(), dst.path, conv, mode, env)
+		m.SrcNilChecks, _ = embeddedPtrs(srcStruct, srcField)
+		m.DstAllocs = append(m.DstAllocs, allocs...)
+		for i, p := range dst.ptrs {
+			m.DstAllocs = append(m.DstAllocs, PathAlloc{Path: p, Type: env.typeString(dst.ptrElems[i])})
+		}
+		*fields = append(*fields, m)
+		copied = append(copied, dst.path)
+	}
+	return len(copied) > 0
+}
+
+// hasPathPrefix 判断 path 是否在 prefixes 中某个字段的下面
+func hasPathPrefix(path string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(path, p+".") {
+			return true
+		}
+	}
+	return false
+}
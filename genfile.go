@@ -0,0 +1,148 @@
+package quickcopy
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+)
+
+// 生成文件名，var _ 声明的原型对应的拷贝函数以及包内所有的辅助函数都写到这里。
+// 原型在测试文件中时生成的也是测试文件，这样才能使用测试文件里定义的类型
+const (
+	genFileName      = "zz_quickcopy_gen.go"
+	genTestFileName  = "zz_quickcopy_gen_test.go"
+	genXTestFileName = "zz_quickcopy_gen_x_test.go" // 外部测试包 package xxx_test
+
+	genFileHeader = "// Code generated by quickcopy. DO NOT EDIT."
+)
+
+// genFile 是一个包的生成文件
+type genFile struct {
+	path  string
+	src   *sourceFile // 第一个用到生成文件的源文件，提供包名和类型信息
+	env   *typeEnv    // 生成原型和辅助函数使用的环境
+	stubs []copyStub
+	funcs []*ast.FuncDecl // 原型生成的拷贝函数
+}
+
+// copyStub 是 var _ func(dst *D, src *S) = CopyD 形式声明的拷贝函数原型
+type copyStub struct {
+	name string
+	pos  token.Position
+	sig  *types.Signature
+	d    *directive
+}
+
+// isGenFile 判断文件是不是生成文件
+func isGenFile(path string) bool {
+	switch filepath.Base(path) {
+	case genFileName, genTestFileName, genXTestFileName:
+		return true
+	}
+	return false
+}
+
+// genFilePath 返回源文件中的原型对应的生成文件
+func genFilePath(src *sourceFile) string {
+	name := genFileName
+	if strings.HasSuffix(src.path, "_test.go") {
+		name = genTestFileName
+		if strings.HasSuffix(src.file.Name.Name, "_test") {
+			name = genXTestFileName
+		}
+	}
+	return filepath.Join(filepath.Dir(src.path), name)
+}
+
+// findCopyStubs 查找带有 // :quickcopy 注释的 var _ func(dst *D, src *S) = CopyD 声明
+func (s *session) findCopyStubs(genDecl *ast.GenDecl, src *sourceFile) []copyStub {
+	res := s.res
+	if genDecl.Tok != token.VAR {
+		return nil
+	}
+
+	var stubs []copyStub
+	for _, spec := range genDecl.Specs {
+		vs := spec.(*ast.ValueSpec)
+		// 不带括号的 var 声明，注释挂在 GenDecl 上
+		doc := vs.Doc
+		if doc == nil && len(genDecl.Specs) == 1 {
+			doc = genDecl.Doc
+		}
+		d, ok := parseDirective(doc, s.cfg.Defaults)
+		if !ok {
+			continue
+		}
+
+		if len(vs.Names) != 1 || vs.Names[0].Name != "_" || len(vs.Values) != 1 || vs.Type == nil {
+			res.errorf(src.pkg.Fset.Position(vs.Pos()), "// :quickcopy stub must be var _ func(dst *D, src *S) = CopyD")
+			continue
+		}
+		name, ok := vs.Values[0].(*ast.Ident)
+		if !ok {
+			res.errorf(src.pkg.Fset.Position(vs.Pos()), "// :quickcopy stub must be assigned a function name")
+			continue
+		}
+		sig, ok := src.pkg.TypesInfo.TypeOf(vs.Type).(*types.Signature)
+		if !ok {
+			res.errorf(src.pkg.Fset.Position(vs.Pos()), "// :quickcopy stub must have a function type")
+			continue
+		}
+
+		s.debugf("Found // :quickcopy stub: %s", name.Name)
+		stubs = append(stubs, copyStub{name: name.Name, pos: src.pkg.Fset.Position(vs.Pos()), sig: sig, d: d})
+	}
+	return stubs
+}
+
+// generateStubs 生成原型对应的拷贝函数，已经生成的原型不再重复生成
+func (s *session) generateStubs(g *genFile) {
+	stubs := g.stubs
+	g.stubs = nil
+	for _, stub := range stubs {
+		g.env.pos = stub.pos
+		fn, fields, err := generateTopLevelFunc(nil, stub.name, stub.sig, stub.d, g.env)
+		if err != nil {
+			s.res.errorf(stub.pos, "%v", err)
+			// 保留已有的生成文件中原来的函数，引用它的代码仍然可以编译
+			if fn := g.env.scope.prevFunc(stub.name); fn != nil {
+				fn = s.adopt(g, fn)
+				g.funcs = append(g.funcs, fn)
+				g.env.scope.roots = append(g.env.scope.roots, fn)
+			}
+			continue
+		}
+		g.funcs = append(g.funcs, fn)
+		g.env.scope.roots = append(g.env.scope.roots, fn)
+		s.res.addFunc(g.path, stub.pos, fn, fields)
+	}
+}
+
+// writeGenFile 输出原型生成的拷贝函数以及包内用到的辅助函数，整体覆盖生成文件
+func (s *session) writeGenFile(g *genFile) {
+	res := s.res
+	scope := g.env.scope
+	helpers := make(map[string]*ast.FuncDecl)
+	decls := g.funcs
+	for name, fn := range scope.helpers {
+		if !scope.reachable[name] {
+			s.debugf("Dropping unused helper: %s", name)
+			continue
+		}
+		helpers[name] = fn
+		decls = append(decls, fn)
+	}
+	if len(decls) == 0 {
+		return
+	}
+	content, err := renderGenFile(g.src.file.Name.Name, g.funcs, helpers, g.env.usedImports(decls), g.env.aliases)
+	if err != nil {
+		res.errorf(token.Position{Filename: g.path}, "render generated file: %v", err)
+		return
+	}
+	res.write(g.path, content)
+
+	s.debugf("Successfully generated file: %s", g.path)
+}
@@ -1,68 +1,46 @@
 package quickcopy
 
 import (
-	"fmt"
 	"go/ast"
 	"go/token"
-	"log"
-	"strings"
-)
-
-func addRequiredImports(file *ast.File, importPath ...string) {
+	"strconv"
 
-	log.Printf("addRequiredImports:%v\n", importPath)
-
-	if len(importPath) == 0 {
-		return
-	}
-	// 需要添加的包
-	requiredImports := make(map[string]bool)
-
-	for _, pkg := range importPath {
-		requiredImports[pkg] = true
-	}
+	"golang.org/x/tools/go/ast/astutil"
+)
 
-	// 查找现有的 import 声明
-	var importDecl *ast.GenDecl
-	for _, decl := range file.Decls {
-		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
-			importDecl = genDecl
+// deleteImports 删除导入的包，带别名的导入也删除
+func deleteImports(fset *token.FileSet, file *ast.File, importPath ...string) {
+	for _, path := range importPath {
+		for _, imp := range file.Imports {
+			if p, err := strconv.Unquote(imp.Path.Value); err != nil || p != path {
+				continue
+			}
+			name := ""
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			astutil.DeleteNamedImport(fset, file, name, path)
 			break
 		}
 	}
+}
 
-	// 如果没有 import 声明，创建一个新的
-	if importDecl == nil {
-		importSpecs := make([]ast.Spec, 0, len(requiredImports))
-		for pkg := range requiredImports {
-			importSpecs = append(importSpecs, &ast.ImportSpec{
-				Path: &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf(`"%s"`, pkg)},
-			})
-		}
-
-		// 将新的 import 声明添加到文件顶部
-		file.Decls = append([]ast.Decl{&ast.GenDecl{
-			Tok:   token.IMPORT,
-			Specs: importSpecs,
-		}}, file.Decls...)
-		return
-	}
-
-	// 如果已有 import 声明，检查并添加缺失的包
+// addRequiredImports 添加缺失的导入，aliases 中的包使用别名
+func addRequiredImports(fset *token.FileSet, file *ast.File, aliases map[string]string, importPath ...string) {
+	// 已经导入的包（包括带别名的）不再重复添加
 	existingImports := make(map[string]bool)
-	for _, spec := range importDecl.Specs {
-		if importSpec, ok := spec.(*ast.ImportSpec); ok {
-			existingImports[strings.Trim(importSpec.Path.Value, `"`)] = true
+	for _, imp := range file.Imports {
+		if path, err := strconv.Unquote(imp.Path.Value); err == nil {
+			existingImports[path] = true
 		}
 	}
 
 	// 添加缺失的包
-	for pkg := range requiredImports {
-		if !existingImports[pkg] {
-			log.Printf("Adding import: %s", pkg)
-			importDecl.Specs = append(importDecl.Specs, &ast.ImportSpec{
-				Path: &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf(`"%s"`, pkg)},
-			})
+	for _, pkg := range importPath {
+		if existingImports[pkg] {
+			continue
 		}
+		astutil.AddNamedImport(fset, file, aliases[pkg], pkg)
+		existingImports[pkg] = true
 	}
 }
@@ -0,0 +1,299 @@
+package quickcopy
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const loadMode = packages.NeedName |
+	packages.NeedFiles |
+	packages.NeedCompiledGoFiles |
+	packages.NeedSyntax |
+	packages.NeedImports |
+	packages.NeedDeps |
+	packages.NeedTypes |
+	packages.NeedTypesInfo
+
+// sourceFile 是一个待处理的 Go 源文件及其所属的包
+type sourceFile struct {
+	path    string
+	file    *ast.File
+	pkg     *packages.Package
+	content []byte // 加载时的原始内容，写回时在此基础上拼接
+
+	changed map[*ast.FuncDecl]bool // 已经重新生成函数体的顶层拷贝函数
+}
+
+// loadSourceFiles 一次性加载 cfg 指定的所有包（包括测试文件），
+// 返回按目录排序的源文件列表，同一目录中非测试文件在测试文件之前，-exclude 排除的文件不在其中，
+// 这些文件以及因为构建约束没有加载的文件所在的目录记录在 s.partial 中
+func (s *session) loadSourceFiles(ctx context.Context) ([]*sourceFile, error) {
+	cfg, res := s.cfg, s.res
+	loadCfg := &packages.Config{
+		Context: ctx,
+		Mode:    loadMode,
+		Dir:     cfg.Dir,
+		Tests:   true,
+	}
+	if len(cfg.Tags) > 0 {
+		loadCfg.BuildFlags = []string{"-tags=" + strings.Join(cfg.Tags, ",")}
+	}
+	patterns := cfg.Patterns
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	pkgs, err := packages.Load(loadCfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	// 开启 Tests 后，同一个文件会同时出现在 p 和 p [p.test] 中，
+	// 选择包含文件最多的那个变体，保证测试文件里定义的类型也能被找到
+	byPath := make(map[string]*sourceFile)
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			// 生成前 var _ 原型引用的函数还不存在，类型错误只在 -v 时输出
+			if e.Kind == packages.TypeError {
+				s.debugf("Package %s: %v", pkg.PkgPath, e)
+				continue
+			}
+			res.errorf(parsePosition(e.Pos), "package %s: %s", pkg.PkgPath, e.Msg)
+		}
+		// 因为构建约束没有加载的文件中也可能有原型
+		for _, path := range pkg.IgnoredFiles {
+			s.partial[filepath.Dir(path)] = true
+		}
+		for _, file := range pkg.Syntax {
+			path := pkg.Fset.Position(file.Package).Filename
+			if cfg.excluded(path) {
+				s.partial[filepath.Dir(path)] = true
+				continue
+			}
+			if old, ok := byPath[path]; ok && len(old.pkg.Syntax) >= len(pkg.Syntax) {
+				continue
+			}
+			byPath[path] = &sourceFile{path: path, file: file, pkg: pkg}
+		}
+	}
+
+	files := make([]*sourceFile, 0, len(byPath))
+	for _, f := range byPath {
+		content, err := os.ReadFile(f.path)
+		if err != nil {
+			return nil, err
+		}
+		f.content = content
+		f.changed = make(map[*ast.FuncDecl]bool)
+		files = append(files, f)
+	}
+	// 测试文件可以使用非测试文件生成的辅助函数，所以先处理非测试文件
+	sort.Slice(files, func(i, j int) bool {
+		a, b := files[i].path, files[j].path
+		if da, db := filepath.Dir(a), filepath.Dir(b); da != db {
+			return da < db
+		}
+		if ta, tb := strings.HasSuffix(a, "_test.go"), strings.HasSuffix(b, "_test.go"); ta != tb {
+			return tb
+		}
+		return a < b
+	})
+	return files, nil
+}
+
+// typeEnv 保存生成代码时需要的类型信息：当前包、当前文件以及需要补充的导入。
+// file 为 nil 表示写入单独的生成文件
+type typeEnv struct {
+	src        *sourceFile
+	pkg        *packages.Package
+	file       *ast.File
+	path       string
+	imports    map[string]bool
+	names      map[string]string     // 导入的包路径 -> 生成的代码中使用的包名
+	aliases    map[string]string     // 生成文件中和其他包同名、需要带别名导入的包
+	converters map[string]*converter // 用户注册的转换函数
+
+	s     *session
+	scope *pkgState      // 所在包已经生成的函数
+	pos   token.Position // 正在生成的拷贝函数原型的位置
+}
+
+func (s *session) newTypeEnv(src *sourceFile) *typeEnv {
+	return &typeEnv{
+		src:        src,
+		pkg:        src.pkg,
+		file:       src.file,
+		path:       src.path,
+		imports:    make(map[string]bool),
+		names:      make(map[string]string),
+		aliases:    make(map[string]string),
+		converters: s.loadConverters(src.pkg),
+		s:          s,
+		scope:      s.scope(src),
+	}
+}
+
+// position 返回 pos 的位置，pos 无效时使用正在生成的拷贝函数原型的位置
+func (e *typeEnv) position(pos token.Pos) token.Position {
+	if pos.IsValid() {
+		return e.pkg.Fset.Position(pos)
+	}
+	return e.pos
+}
+
+// warnf 记录一条警告，生成继续
+func (e *typeEnv) warnf(pos token.Pos, format string, args ...any) {
+	e.s.res.warnf(e.position(pos), format, args...)
+}
+
+// errorf 记录一条错误
+func (e *typeEnv) errorf(pos token.Pos, format string, args ...any) {
+	e.s.res.errorf(e.position(pos), format, args...)
+}
+
+func (e *typeEnv) debugf(format string, args ...any) {
+	e.s.debugf(format, args...)
+}
+
+// helperEnv 返回生成辅助函数使用的环境。辅助函数都写到包的生成文件中，
+// 类型使用包名而不是当前文件的导入别名，需要的导入也记在生成文件上
+func (e *typeEnv) helperEnv() *typeEnv {
+	g := e.s.genFile(e.src)
+	g.env.pos = e.pos
+	return g.env
+}
+
+// addImport 记录生成代码依赖的包，文件中已有的导入也要记录，重新生成函数体时据此保留
+func (e *typeEnv) addImport(path string) {
+	if path != "" && !e.imports[path] {
+		e.debugf("Adding import: %s", path)
+		e.imports[path] = true
+	}
+}
+
+// usedImports 返回 decls 中用到的依赖包。没有写入生成文件的辅助函数也可能添加过导入，
+// 按包名检查是否使用
+func (e *typeEnv) usedImports(decls []*ast.FuncDecl) []string {
+	used := make(map[string]bool)
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			if x, ok := n.X.(*ast.Ident); ok {
+				used[x.Name] = true
+			}
+		case *ast.Ident:
+			// 函数签名中的类型直接用类型的写法作为名字，如 remote.Address
+			if strings.Contains(n.Name, ".") {
+				if expr, err := parser.ParseExpr(n.Name); err == nil {
+					ast.Inspect(expr, visit)
+				}
+			}
+		}
+		return true
+	}
+	for _, decl := range decls {
+		ast.Inspect(decl, visit)
+	}
+
+	var list []string
+	for _, path := range e.importList() {
+		name, ok := e.names[path]
+		if !ok {
+			name = pathpkg.Base(path)
+		}
+		if used[name] {
+			list = append(list, path)
+		}
+	}
+	return list
+}
+
+// importList 返回排好序的依赖包列表
+func (e *typeEnv) importList() []string {
+	list := make([]string, 0, len(e.imports))
+	for path := range e.imports {
+		list = append(list, path)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// qualifier 决定类型在当前文件中的包前缀，优先使用文件里的导入别名
+func (e *typeEnv) qualifier(p *types.Package) string {
+	if p == nil || p.Path() == e.pkg.Types.Path() {
+		return ""
+	}
+	if e.file == nil {
+		e.addImport(p.Path())
+		return e.importName(p)
+	}
+	for _, imp := range e.file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || path != p.Path() {
+			continue
+		}
+		e.imports[path] = true
+		if imp.Name != nil {
+			if imp.Name.Name == "." {
+				return ""
+			}
+			return imp.Name.Name
+		}
+		return p.Name()
+	}
+	e.addImport(p.Path())
+	return p.Name()
+}
+
+// importName 返回生成文件中 p 使用的包名。包直接导入的其他包或者生成文件已经用到的其他包中有同名的，
+// 依次加上路径中上一级目录的名字区分，如 play/a/models 和 play/b/models 分别是 amodels 和 bmodels。
+// 辅助函数的名字也由此得到，不同的类型不会生成同名的辅助函数
+func (e *typeEnv) importName(p *types.Package) string {
+	if name, ok := e.names[p.Path()]; ok {
+		return name
+	}
+	taken := make(map[string]bool)
+	for path, imp := range e.pkg.Imports {
+		if path != p.Path() {
+			taken[imp.Name] = true
+		}
+	}
+	for _, name := range e.names {
+		taken[name] = true
+	}
+	name := p.Name()
+	elems := strings.Split(p.Path(), "/")
+	for i := len(elems) - 2; i >= 0 && taken[name]; i-- {
+		name = strings.ToLower(strings.Map(identRune, elems[i])) + name
+	}
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s%d", p.Name(), i)
+	}
+	e.names[p.Path()] = name
+	if name != p.Name() {
+		e.aliases[p.Path()] = name
+	}
+	return name
+}
+
+// typeString 返回类型在当前文件中的写法
+func (e *typeEnv) typeString(t types.Type) string {
+	return types.TypeString(t, e.qualifier)
+}
+
+// isAccessible 判断字段能否在当前包中访问
+func (e *typeEnv) isAccessible(field *types.Var) bool {
+	return field.Exported() || field.Pkg() == nil || field.Pkg().Path() == e.pkg.Types.Path()
+}
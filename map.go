@@ -0,0 +1,153 @@
+package quickcopy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+func isMapType(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Map)
+	return ok
+}
+
+// getMapCopyFuncName 获取 map 拷贝函数名
+func getMapCopyFuncName(srcKey, srcElem, dstKey, dstElem string) string {
+	return fmt.Sprintf("copyMap%sTo%sFromMap%sTo%s",
+		sanitizeTypeName(dstKey),
+		sanitizeTypeName(dstElem),
+		sanitizeTypeName(srcKey),
+		sanitizeTypeName(srcElem))
+}
+
+func handleMapConversion(srcType, dstType types.Type, opts copyOptions, mode errMode, env *typeEnv) (conversion, bool) {
+	env = env.helperEnv()
+	srcMap := srcType.Underlying().(*types.Map)
+	dstMap := dstType.Underlying().(*types.Map)
+
+	// 分别生成 key 和 value 的转换
+	keyConv, ok := getTypeConversion(srcMap.Key(), dstMap.Key(), opts, mode, env)
+	if !ok {
+		env.debugf("No key conversion for %s to %s", env.typeString(srcType), env.typeString(dstType))
+		return conversion{}, false
+	}
+	elemConv, ok := getTypeConversion(srcMap.Elem(), dstMap.Elem(), opts, mode, env)
+	if !ok {
+		env.debugf("No value conversion for %s to %s", env.typeString(srcType), env.typeString(dstType))
+		return conversion{}, false
+	}
+
+	suffix := elemConv.Opts
+	if suffix == "" {
+		suffix = keyConv.Opts
+	}
+	funcName := generateMapCopyFunc(srcMap, dstMap, keyConv, elemConv, suffix, mode, env)
+	if funcName == "" {
+		return conversion{}, false
+	}
+	if keyConv.Fallible || elemConv.Fallible {
+		return conversion{Func: funcName, Fallible: true, ErrSep: errSepNested, Opts: suffix}, true
+	}
+	return conversion{Func: funcName, Opts: suffix}, true
+}
+
+// suffix 是 key 或 value 用到的结构体拷贝函数的选项后缀
+func generateMapCopyFunc(srcMap, dstMap *types.Map, keyConv, elemConv conversion, suffix string, mode errMode, env *typeEnv) string {
+	srcKey, srcElem := env.typeString(srcMap.Key()), env.typeString(srcMap.Elem())
+	dstKey, dstElem := env.typeString(dstMap.Key()), env.typeString(dstMap.Elem())
+	funcName := getMapCopyFuncName(srcKey, srcElem, dstKey, dstElem) + suffix
+	fallible := keyConv.Fallible || elemConv.Fallible
+	if fallible {
+		funcName += mode.suffix()
+	}
+
+	if env.scope.hasHelper(funcName) {
+		env.debugf("Map function %s already generated", funcName)
+		return funcName
+	}
+
+	// 转换失败的条目返回带 key 的错误，收集错误时跳过该条目
+	onErr := func(conv conversion) string {
+		stmt := mode.onErr("nil, ", "[%v]", conv, env, "k")
+		if mode == errJoin {
+			stmt += "\ncontinue"
+		}
+		return stmt
+	}
+
+	// 结构体需要先拷贝到临时变量，其他转换直接写成表达式
+	var body strings.Builder
+	key := mapEntryExpr(&body, "k", "dk", dstKey, keyConv, onErr)
+	elem := mapEntryExpr(&body, "v", "dv", dstElem, elemConv, onErr)
+	fmt.Fprintf(&body, "dst[%s] = %s", key, elem)
+
+	code := fmt.Sprintf(`
+	package main
+// %s 是自动生成的 map 拷贝函数
+func %s(src map[%s]%s) map[%s]%s {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[%s]%s, len(src))
+	for k, v := range src {
+		%s
+	}
+	return dst
+}`, funcName, funcName, srcKey, srcElem, dstKey, dstElem, dstKey, dstElem, body.String())
+
+	if fallible {
+		code = fmt.Sprintf(`
+	package main
+// %s 是自动生成的 map 拷贝函数
+func %s(src map[%s]%s) (map[%s]%s, error) {
+	if src == nil {
+		return nil, nil
+	}
+	dst := make(map[%s]%s, len(src))
+	%s
+	for k, v := range src {
+		%s
+	}
+	return dst, %s
+}`, funcName, funcName, srcKey, srcElem, dstKey, dstElem, dstKey, dstElem, mode.errDecl(), body.String(), mode.returnErr(env))
+	}
+
+	// 安全解析生成的代码
+	fset := token.NewFileSet()
+	parsedFile, err := parser.ParseFile(fset, "", code, parser.ParseComments)
+	if err != nil {
+		env.errorf(token.NoPos, "parse generated map function: %v", err)
+		return ""
+	}
+
+	if len(parsedFile.Decls) == 0 {
+		env.errorf(token.NoPos, "generated map function is empty")
+		return ""
+	}
+
+	if fn, ok := parsedFile.Decls[0].(*ast.FuncDecl); ok {
+		addGeneratedFunction(funcName, fn, env)
+		return funcName
+	}
+	return ""
+}
+
+// mapEntryExpr 返回转换后的 key 或 value 表达式，必要时向 body 写入临时变量。
+// 转换可能失败时先转换到临时变量，失败时执行 onErr 返回的语句
+func mapEntryExpr(body *strings.Builder, srcVar, tmpVar, dstType string, conv conversion, onErr func(conversion) string) string {
+	switch {
+	case conv.Fallible:
+		fmt.Fprintf(body, "var %s %s\nif %s; err != nil {\n%s\n}\n",
+			tmpVar, dstType, generateFallibleConversion(srcVar, tmpVar, conv), onErr(conv))
+		return tmpVar
+	case conv.IsStruct:
+		fmt.Fprintf(body, "var %s %s\n%s\n", tmpVar, dstType, generateElementConversion(srcVar, tmpVar, conv))
+		return tmpVar
+	case conv.Func != "":
+		return fmt.Sprintf("%s(%s)", conv.Func, srcVar)
+	}
+	return srcVar
+}
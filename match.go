@@ -0,0 +1,165 @@
+package quickcopy
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// fieldMatcher 决定目标字段和源字段按什么规则对应
+type fieldMatcher struct {
+	ignoreCase bool       // --ignore-case：strings.EqualFold
+	normalized bool       // --match=normalized：拆成单词后比较，user_id、UserID、UserId 都相同
+	strip      [][]string // --strip=Src,DTO,PB：比较前去掉的前缀、后缀，按单词拆好
+	tags       []string   // --match-tag=json,db：按这些标签的名字匹配字段
+	flatten    bool       // --flatten：AddressCity 和 Address.City 对应
+}
+
+// equal 判断两个字段名（或者 quickcopy 标签指定的名字）是否对应
+func (m fieldMatcher) equal(a, b string) bool {
+	if m.normalized {
+		return sameWords(m.stripAffixes(splitWords(a)), m.stripAffixes(splitWords(b)))
+	}
+	if m.ignoreCase {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// stripAffixes 去掉一个匹配的前缀和一个匹配的后缀，至少保留一个单词
+func (m fieldMatcher) stripAffixes(words []string) []string {
+	for _, affix := range m.strip {
+		if len(words) > len(affix) && sameWords(words[:len(affix)], affix) {
+			words = words[len(affix):]
+			break
+		}
+	}
+	for _, affix := range m.strip {
+		if len(words) > len(affix) && sameWords(words[len(words)-len(affix):], affix) {
+			words = words[:len(words)-len(affix)]
+			break
+		}
+	}
+	return words
+}
+
+func sameWords(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// commonInitialisms 是 Go 命名中常见的缩写，连续的大写字母按它们拆分，如 HTTPURL -> HTTP URL
+var commonInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DB", "DNS", "DTO", "EOF", "GUID", "HTML", "HTTP", "HTTPS",
+	"ID", "IP", "JSON", "LHS", "PB", "QPS", "RAM", "RHS", "RPC", "SKU", "SLA", "SMTP", "SQL", "SSH",
+	"TCP", "TLS", "TTL", "UDP", "UI", "UID", "URI", "URL", "UUID", "VM", "XML", "XMPP", "XSRF", "XSS",
+}
+
+// splitWords 把标识符拆成小写单词：下划线、短横线是分隔符，小写到大写、字母到数字是边界，
+// 连续的大写字母（如 HTTPURL）先按 commonInitialisms 拆分，拆不开时作为一个单词。
+// 缩写后面只跟一个小写字母时属于缩写，如 UserIDs、IPv4 和 user_ids、ipv4 相同
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '_' || r == '-':
+			i++
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			words = append(words, string(runes[i:j]))
+			i = j
+		case unicode.IsUpper(r):
+			j := i
+			for j < len(runes) && unicode.IsUpper(runes[j]) {
+				j++
+			}
+			// 缩写后面的单个小写字母是复数的 s 或者 IPv4 中的 v，和缩写是一个单词
+			if j-i > 1 && j < len(runes) && unicode.IsLower(runes[j]) && (j+1 == len(runes) || !unicode.IsLower(runes[j+1])) {
+				if initialisms, ok := splitInitialisms(string(runes[i:j])); ok {
+					initialisms[len(initialisms)-1] += string(runes[j])
+					words = append(words, initialisms...)
+					i = j + 1
+					continue
+				}
+			}
+			// 否则最后一个大写字母属于下一个单词，如 HTTPServer
+			if j < len(runes) && unicode.IsLower(runes[j]) && j-i > 1 {
+				j--
+			}
+			if j-i == 1 {
+				k := j
+				for k < len(runes) && unicode.IsLower(runes[k]) {
+					k++
+				}
+				words = append(words, strings.ToLower(string(runes[i:k])))
+				i = k
+				continue
+			}
+			initialisms, _ := splitInitialisms(string(runes[i:j]))
+			words = append(words, initialisms...)
+			i = j
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsUpper(runes[j]) && !unicode.IsDigit(runes[j]) && runes[j] != '_' && runes[j] != '-' {
+				j++
+			}
+			words = append(words, strings.ToLower(string(runes[i:j])))
+			i = j
+		}
+	}
+	return words
+}
+
+// splitInitialisms 把连续的大写字母按 commonInitialisms 拆分，优先匹配最长的缩写。
+// 有不是已知缩写的部分时第二个返回值为 false
+func splitInitialisms(s string) ([]string, bool) {
+	var words []string
+	for s != "" {
+		best := ""
+		for _, in := range commonInitialisms {
+			if len(in) > len(best) && strings.HasPrefix(s, in) {
+				best = in
+			}
+		}
+		if best == "" {
+			// 剩下的部分不是已知缩写，整体作为一个单词
+			return append(words, strings.ToLower(s)), false
+		}
+		words = append(words, strings.ToLower(best))
+		s = s[len(best):]
+	}
+	return words, true
+}
+
+// matchOptions 解析 --match、--strip 的值
+func matchOptions(match string, stripList []string) (normalized bool, strip [][]string, err error) {
+	switch match {
+	case "", "exact":
+	case "normalized":
+		normalized = true
+	default:
+		err = fmt.Errorf("unknown --match=%s, want exact or normalized", match)
+	}
+	for _, affix := range stripList {
+		if words := splitWords(strings.TrimSpace(affix)); len(words) > 0 {
+			strip = append(strip, words)
+		}
+	}
+	// 去掉前缀、后缀需要按单词比较
+	if len(strip) > 0 {
+		normalized = true
+	}
+	return normalized, strip, err
+}
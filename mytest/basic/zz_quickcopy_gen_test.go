@@ -0,0 +1,35 @@
+// Code generated by quickcopy. DO NOT EDIT.
+
+package basic
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// copySliceIntFromSliceString 是自动生成的切片拷贝函数
+func copySliceIntFromSliceString(src []string) []int {
+	if src == nil {
+		return nil
+	}
+	dst := make([]int, len(src))
+	for i := range src {
+		dst[i] = func(s string) int {
+			i, _ := strconv.Atoi(s)
+			return i
+		}(src[i])
+	}
+	return dst
+}
+
+// copySliceStringFromSliceInt 是自动生成的切片拷贝函数
+func copySliceStringFromSliceInt(src []int) []string {
+	if src == nil {
+		return nil
+	}
+	dst := make([]string, len(src))
+	for i := range src {
+		dst[i] = fmt.Sprint(src[i])
+	}
+	return dst
+}
@@ -0,0 +1,576 @@
+package mytest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/antlabs/quickcopy"
+)
+
+// 提交的生成代码必须和当前生成器的输出一致
+func TestGeneratedCodeUpToDate(t *testing.T) {
+	out := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(out)
+
+	var diff bytes.Buffer
+	if quickcopy.Check(".", &diff) {
+		t.Errorf("generated code is stale, run quickcopy in mytest:\n%s", diff.String())
+	}
+}
+
+// explain 输出字段映射，-exclude 排除的文件不处理
+func TestExplain(t *testing.T) {
+	var buf bytes.Buffer
+	quickcopy.Explain(quickcopy.Config{
+		Dir:      ".",
+		Patterns: []string{"./copy_error", "./genfile"},
+		Exclude:  []string{"genfile"},
+	}, &buf)
+
+	out := buf.String()
+	for _, want := range []string{
+		"copy_error/copy_error_test.go:48: func CopyUser(dst *User, src *UserReq) error\n",
+		"\tAge <- Age (strconv.Atoi)\n",
+		"\tAddress <- Address (copyAddressFromAddressReqErr)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("explain output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "genfile/") {
+		t.Errorf("excluded file should not be explained:\n%s", out)
+	}
+}
+
+// 原型有问题时返回带位置的诊断信息，不影响其他拷贝函数，也不修改文件
+func TestGenerateDiagnostics(t *testing.T) {
+	res, err := quickcopy.Generate(context.Background(), quickcopy.Config{
+		Dir:      ".",
+		Patterns: []string{"./testdata/badproto"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"bad.go:14:1: error: copy function CopyMissingSrc must have exactly two parameters",
+		"bad.go:18:1: error: copy function CopyUnnamed must name its parameters, e.g. func CopyUnnamed(dst *D, src *S)",
+		"bad.go:22:1: warning: skipping field Age: cannot convert int to int8",
+		"bad.go:22:1: warning: copy function CopyUserDTO: source fields not used: Age (map them or ignore with _ = Field)",
+	}
+	if len(res.Diagnostics) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %v", len(res.Diagnostics), len(want), res.Diagnostics)
+	}
+	for i, d := range res.Diagnostics {
+		d.Pos.Filename = filepath.Base(d.Pos.Filename)
+		if d.String() != want[i] {
+			t.Errorf("diagnostic %d = %q, want %q", i, d.String(), want[i])
+		}
+	}
+	if res.Err() == nil {
+		t.Error("Err() should report the error diagnostic")
+	}
+
+	if len(res.Files) != 1 || len(res.Files[0].Funcs) != 1 {
+		t.Fatalf("want one file with one function, got %+v", res.Files)
+	}
+	f := res.Files[0]
+	if !f.Changed || !strings.Contains(string(f.Content), "dst.Name = src.Name") {
+		t.Errorf("CopyUserDTO should be generated in memory:\n%s", f.Content)
+	}
+	if fn := f.Funcs[0]; fn.Name != "CopyUserDTO" || fn.Signature != "func CopyUserDTO(dst *UserDTO, src *User)" {
+		t.Errorf("unexpected function %+v", fn)
+	}
+}
+
+// 导入的包中未导出的转换函数无法调用，跳过并给出警告，定义它的包内仍然可以使用
+func TestUnexportedConverter(t *testing.T) {
+	res, err := quickcopy.Generate(context.Background(), quickcopy.Config{
+		Dir:      ".",
+		Patterns: []string{"./testdata/privconv/..."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Diagnostics) != 1 {
+		t.Fatalf("want one diagnostic, got %v", res.Diagnostics)
+	}
+	d := res.Diagnostics[0]
+	d.Pos.Filename = filepath.Base(d.Pos.Filename)
+	want := "money.go:15:6: warning: converter github.com/antlabs/quickcopy/mytest/testdata/privconv/money.toCents is not exported, skipping it outside package money"
+	if d.String() != want {
+		t.Errorf("got %q, want %q", d.String(), want)
+	}
+
+	for _, f := range res.Files {
+		content := string(f.Content)
+		switch filepath.Base(f.Path) {
+		case "order.go":
+			if strings.Contains(content, "toCents") || !strings.Contains(content, "money.FormatCents(src.Total)") {
+				t.Errorf("only the exported converter should be used:\n%s", content)
+			}
+		case "zz_quickcopy_gen.go":
+			if !strings.Contains(content, "dst.Amount = toCents(src.Amount)") {
+				t.Errorf("unexported converter should be used in its own package:\n%s", content)
+			}
+		}
+	}
+}
+
+// ctx 取消后不再生成
+func TestGenerateCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := quickcopy.Generate(ctx, quickcopy.Config{Dir: ".", Patterns: []string{"./copy_error"}}); err == nil {
+		t.Error("want error after ctx is canceled")
+	}
+}
+
+// --strict 时列出所有没有来源的目标字段，拷贝函数不生成
+func TestStrictDiagnostics(t *testing.T) {
+	res, err := quickcopy.Generate(context.Background(), quickcopy.Config{
+		Dir:      ".",
+		Patterns: []string{"./testdata/strict"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Diagnostics) != 1 {
+		t.Fatalf("want one diagnostic, got %v", res.Diagnostics)
+	}
+	d := res.Diagnostics[0]
+	d.Pos.Filename = filepath.Base(d.Pos.Filename)
+	want := "strict.go:20:1: error: copy function CopyUserView: destination fields without source: CreatedBy, Avatar (map them or ignore with -Field)"
+	if d.String() != want {
+		t.Errorf("got %q, want %q", d.String(), want)
+	}
+	if len(res.Changed()) != 0 {
+		t.Errorf("nothing should be generated, got %v", res.Changed())
+	}
+}
+
+// --strict-source 时没有被使用的源字段是错误，_ = Field 和 quickcopy:"-" 的字段除外
+func TestStrictSourceDiagnostics(t *testing.T) {
+	res, err := quickcopy.Generate(context.Background(), quickcopy.Config{
+		Dir:      ".",
+		Patterns: []string{"./testdata/unused"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Diagnostics) != 1 {
+		t.Fatalf("want one diagnostic, got %v", res.Diagnostics)
+	}
+	d := res.Diagnostics[0]
+	d.Pos.Filename = filepath.Base(d.Pos.Filename)
+	want := "unused.go:15:1: error: copy function CopyUserView: source fields not used: Email (map them or ignore with _ = Field)"
+	if d.String() != want {
+		t.Errorf("got %q, want %q", d.String(), want)
+	}
+}
+
+func TestExprRuleDiagnostics(t *testing.T) {
+	res, err := quickcopy.Generate(context.Background(), quickcopy.Config{
+		Dir:      ".",
+		Patterns: []string{"./testdata/badexpr"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 表达式规则在生成时做类型检查：拼错的字段、类型不匹配、常量溢出，按规则的声明顺序报告。
+	// 规则出错时拷贝函数不生成，保留原来的函数体
+	want := []string{
+		`badexpr.go:15:15: error: mapping rule FullName = src.FirstName + " " + src.LastNmae: src.LastNmae undefined (type *User has no field or method LastNmae)`,
+		"badexpr.go:15:62: error: mapping rule Age = src.FirstName: cannot use src.FirstName (variable of type string) as int value in assignment",
+		"badexpr.go:15:83: error: mapping rule Level = 300: cannot use 300 (untyped int constant) as int8 value in assignment (overflows)",
+		"badexpr.go:16:1: error: copy function CopyUserView: invalid mapping rules",
+	}
+	var got []string
+	for _, d := range res.Diagnostics {
+		if strings.Contains(d.Message, "mapping rule") {
+			d.Pos.Filename = filepath.Base(d.Pos.Filename)
+			got = append(got, d.String())
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if len(res.Changed()) != 0 {
+		t.Errorf("nothing should be generated, got %v", res.Changed())
+	}
+}
+
+func TestDirectiveDiagnostics(t *testing.T) {
+	res, err := quickcopy.Generate(context.Background(), quickcopy.Config{
+		Dir:      ".",
+		Patterns: []string{"./testdata/baddirective"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 诊断信息指向指令中出错的词，指令有错误时拷贝函数不生成
+	want := []string{
+		"baddirective.go:13:15: error: unknown option --strictt",
+		`baddirective.go:14:17: error: invalid mapping rule "Age", want Field = Source`,
+		"baddirective.go:15:4: error: duplicate mapping rule for Name",
+		`baddirective.go:15:16: error: invalid ignore "-", want -Field`,
+		"baddirective.go:16:11: error: unterminated string literal",
+		"baddirective.go:13:25: error: unknown --match=fuzzy, want exact or normalized",
+		"baddirective.go:17:1: error: copy function CopyUserView: invalid // :quickcopy directive",
+	}
+	var got []string
+	for _, d := range res.Diagnostics {
+		d.Pos.Filename = filepath.Base(d.Pos.Filename)
+		got = append(got, d.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if res.Err() == nil || len(res.Changed()) != 0 {
+		t.Errorf("invalid directive should fail without generating, got %v", res.Changed())
+	}
+}
+
+// 相同的输入每次生成的代码和诊断信息都相同，规则按声明顺序输出
+func TestGenerateDeterministic(t *testing.T) {
+	generate := func() *quickcopy.Result {
+		res, err := quickcopy.Generate(context.Background(), quickcopy.Config{
+			Dir:      ".",
+			Patterns: []string{"./testdata/ordered"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	first := generate()
+	if len(first.Files) != 1 {
+		t.Fatalf("want one file, got %+v", first.Files)
+	}
+	content := string(first.Files[0].Content)
+	last := -1
+	for _, line := range []string{"dst.Zip = src.Code", "dst.City = src.Town", "dst.Name = strings.ToUpper(src.Nick)", "dst.Age = int(src.Years)"} {
+		i := strings.Index(content, line)
+		if i < last {
+			t.Errorf("%q is out of declaration order:\n%s", line, content)
+		}
+		last = i
+	}
+
+	var got []string
+	for _, d := range first.Diagnostics {
+		got = append(got, d.Message)
+	}
+	want := []string{
+		"ignored destination field not found: Remark",
+		"ignored source field not found: Phone",
+		"ignored source field not found: Fax",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	for i := 0; i < 3; i++ {
+		res := generate()
+		if string(res.Files[0].Content) != content {
+			t.Fatalf("run %d generated different code:\n%s\nwant:\n%s", i, res.Files[0].Content, content)
+		}
+		if !reflect.DeepEqual(res.Diagnostics, first.Diagnostics) {
+			t.Fatalf("run %d diagnostics = %v, want %v", i, res.Diagnostics, first.Diagnostics)
+		}
+	}
+}
+
+// 辅助函数在包内共用：同一个包只在生成文件中生成一次，不同的包各自生成，
+// 以前的版本追加到源文件中的辅助函数被删除
+func TestHelperScopes(t *testing.T) {
+	res, err := quickcopy.Generate(context.Background(), quickcopy.Config{
+		Dir:      ".",
+		Patterns: []string{"./testdata/scopes/..."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Diagnostics) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", res.Diagnostics)
+	}
+
+	want := map[string]int{
+		"a/order.go":            0,
+		"a/refund.go":           0,
+		"a/zz_quickcopy_gen.go": 1,
+		"b/order.go":            0,
+		"b/zz_quickcopy_gen.go": 1,
+	}
+	got := make(map[string]int)
+	for _, f := range res.Files {
+		name := filepath.Base(filepath.Dir(f.Path)) + "/" + filepath.Base(f.Path)
+		content := string(f.Content)
+		got[name] = strings.Count(content, "func copySliceItemFromSliceItemReq(")
+		if name != "a/zz_quickcopy_gen.go" && name != "b/zz_quickcopy_gen.go" && strings.Contains(content, "func copyItemFromItemReq(") {
+			t.Errorf("%s should not define helpers:\n%s", name, content)
+		}
+		if name == "a/refund.go" && strings.Contains(content, "strconv") {
+			t.Errorf("import used only by the removed helper should be deleted:\n%s", content)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("helper definitions per file = %v, want %v", got, want)
+	}
+}
+
+// 非测试文件的原型和测试文件的拷贝函数用到同一个辅助函数时，辅助函数只生成在非测试文件的生成文件中
+func TestTestFileHelpers(t *testing.T) {
+	res, err := quickcopy.Generate(context.Background(), quickcopy.Config{
+		Dir:      ".",
+		Patterns: []string{"./testdata/teststub"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Diagnostics) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", res.Diagnostics)
+	}
+
+	got := make(map[string]int)
+	for _, f := range res.Files {
+		if n := strings.Count(string(f.Content), "func copyAddrFromAddrReq("); n > 0 {
+			got[filepath.Base(f.Path)] = n
+		}
+	}
+	want := map[string]int{"zz_quickcopy_gen.go": 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("helper definitions per file = %v, want %v", got, want)
+	}
+}
+
+// 每次 Generate 使用独立的状态，并发调用的结果和依次调用相同
+func TestGenerateConcurrent(t *testing.T) {
+	patterns := [][]string{{"./testdata/scopes/..."}, {"./testdata/ordered"}, {"./copy_pointer"}}
+	generate := func(p []string) map[string]string {
+		res, err := quickcopy.Generate(context.Background(), quickcopy.Config{Dir: ".", Patterns: p})
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		files := make(map[string]string)
+		for _, f := range res.Files {
+			files[f.Path] = string(f.Content)
+		}
+		return files
+	}
+
+	want := make([]map[string]string, len(patterns))
+	for i, p := range patterns {
+		want[i] = generate(p)
+	}
+
+	got := make([]map[string]string, len(patterns))
+	var wg sync.WaitGroup
+	for i, p := range patterns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got[i] = generate(p)
+		}()
+	}
+	wg.Wait()
+	for i := range patterns {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("concurrent Generate(%v) differs from sequential run", patterns[i])
+		}
+	}
+}
+
+// 没有顶层拷贝函数使用的辅助函数不再生成，不再需要的生成文件删除
+func TestOrphanHelpers(t *testing.T) {
+	res, err := quickcopy.Generate(context.Background(), quickcopy.Config{
+		Dir:      ".",
+		Patterns: []string{"./testdata/orphans/..."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Diagnostics) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", res.Diagnostics)
+	}
+
+	files := make(map[string]*quickcopy.FileResult)
+	for _, f := range res.Files {
+		files[filepath.Base(filepath.Dir(f.Path))+"/"+filepath.Base(f.Path)] = f
+	}
+	gen := files["a/zz_quickcopy_gen.go"]
+	if gen == nil || !gen.Changed {
+		t.Fatalf("a/zz_quickcopy_gen.go should be rewritten, got %+v", gen)
+	}
+	content := string(gen.Content)
+	if !strings.Contains(content, "func copyAddressFromAddressReq(") {
+		t.Errorf("reachable helper is missing:\n%s", content)
+	}
+	if strings.Contains(content, "copySliceStringFromSliceInt") || strings.Contains(content, "strconv") {
+		t.Errorf("orphaned helper and its import should be removed:\n%s", content)
+	}
+
+	if f := files["b/zz_quickcopy_gen.go"]; f == nil || !f.Removed || !f.Changed || f.Content != nil {
+		t.Errorf("b/zz_quickcopy_gen.go should be removed, got %+v", f)
+	}
+}
+
+// 以前的版本用 go/printer 写回的文件（mytest/basic 原来的样子）丢掉或者挪走了辅助函数的文档注释，
+// 和生成文件中的辅助函数同名的函数也要删除，否则重复定义
+func TestLegacyHelpers(t *testing.T) {
+	res, err := quickcopy.Generate(context.Background(), quickcopy.Config{
+		Dir:      ".",
+		Patterns: []string{"./testdata/legacyhelpers"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Diagnostics) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", res.Diagnostics)
+	}
+
+	defs := make(map[string]int)
+	for _, f := range res.Files {
+		content := string(f.Content)
+		for _, fn := range []string{"copySliceStringFromSliceInt", "copySliceIntFromSliceString"} {
+			defs[fn] += strings.Count(content, "func "+fn+"(")
+		}
+		if filepath.Base(f.Path) == "basic_test.go" && strings.Contains(content, `"fmt"`) {
+			t.Errorf("import used only by the removed helper should be deleted:\n%s", content)
+		}
+	}
+	// copySliceIntFromSliceString 的拷贝函数已经没有 // :quickcopy 注释，不会重新生成，保留在原来的文件中
+	want := map[string]int{"copySliceStringFromSliceInt": 1, "copySliceIntFromSliceString": 1}
+	if !reflect.DeepEqual(defs, want) {
+		t.Errorf("helper definitions = %v, want %v", defs, want)
+	}
+}
+
+// 原型生成失败时保留生成文件中原来的拷贝函数以及它用到的辅助函数，Run 不写入出错的包
+func TestKeepFailedStub(t *testing.T) {
+	cfg := quickcopy.Config{Dir: ".", Patterns: []string{"./testdata/keepgen"}}
+	res, err := quickcopy.Generate(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Err() == nil {
+		t.Fatal("want an error for the --strict stub")
+	}
+	var gen *quickcopy.FileResult
+	for _, f := range res.Files {
+		if filepath.Base(f.Path) == "zz_quickcopy_gen.go" {
+			gen = f
+		}
+	}
+	if gen == nil || gen.Removed {
+		t.Fatalf("generated file should be kept, got %+v", gen)
+	}
+	content := string(gen.Content)
+	for _, fn := range []string{"CopyItemView", "CopyUserView", "copyProfileViewFromProfile", "copySliceStringFromSliceInt"} {
+		if !strings.Contains(content, "func "+fn+"(") {
+			t.Errorf("%s is missing:\n%s", fn, content)
+		}
+	}
+
+	out := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(out)
+	paths, err := quickcopy.Run(cfg, true)
+	if err == nil || len(paths) != 0 {
+		t.Errorf("Run should not write a package with errors, got %v, %v", paths, err)
+	}
+}
+
+// -exclude 排除了原型所在的文件时，生成文件中的函数全部保留，生成文件也不删除
+func TestKeepExcludedStubs(t *testing.T) {
+	for _, exclude := range [][]string{{"user.go"}, {"user.go", "item.go"}} {
+		res, err := quickcopy.Generate(context.Background(), quickcopy.Config{
+			Dir:      "./testdata/keepgen",
+			Patterns: []string{"."},
+			Exclude:  exclude,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Diagnostics) != 0 {
+			t.Fatalf("unexpected diagnostics: %v", res.Diagnostics)
+		}
+		if len(res.Files) != 1 || res.Files[0].Removed {
+			t.Fatalf("exclude %v: generated file should be kept, got %+v", exclude, res.Files)
+		}
+		content := string(res.Files[0].Content)
+		for _, fn := range []string{"CopyItemView", "CopyUserView", "copyProfileViewFromProfile", "copySliceStringFromSliceInt"} {
+			if !strings.Contains(content, "func "+fn+"(") {
+				t.Errorf("exclude %v: %s is missing:\n%s", exclude, fn, content)
+			}
+		}
+		if !strings.Contains(content, `import "fmt"`) {
+			t.Errorf("exclude %v: import of the kept helper is missing:\n%s", exclude, content)
+		}
+	}
+}
+
+// Write 先写好所有临时文件再重命名：任何一个临时文件写入失败时所有文件都不变，也不留下临时文件；
+// 成功时保留原来的权限
+func TestWriteAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "user.go")
+	if err := os.WriteFile(path, []byte("package old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// 目录不存在，临时文件一定写入失败
+	missing := filepath.Join(dir, "missing", "zz_quickcopy_gen.go")
+
+	res := &quickcopy.Result{Files: []*quickcopy.FileResult{
+		{Path: path, Content: []byte("package user\n"), Changed: true},
+		{Path: missing, Content: []byte("package missing\n"), Changed: true},
+	}}
+	paths, err := res.Write()
+	if err == nil {
+		t.Fatal("writing into a missing directory should fail")
+	}
+	if len(paths) != 0 {
+		t.Errorf("nothing should be written, got %v", paths)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "package old\n" {
+		t.Errorf("failed write should leave %s untouched, content = %q", path, got)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("temporary files left behind: %v", names)
+	}
+
+	res.Files = res.Files[:1]
+	if paths, err = res.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(paths, []string{path}) {
+		t.Errorf("written = %v, want %v", paths, []string{path})
+	}
+	if got, _ := os.ReadFile(path); string(got) != "package user\n" {
+		t.Errorf("content = %q", got)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+}
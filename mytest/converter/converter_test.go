@@ -0,0 +1,100 @@
+package converter
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/antlabs/quickcopy/mytest/converter/money"
+)
+
+type Status int
+
+const (
+	StatusActive Status = iota + 1
+	StatusBanned
+)
+
+// :quickcopy-converter
+func statusName(s Status) string {
+	switch s {
+	case StatusActive:
+		return "active"
+	case StatusBanned:
+		return "banned"
+	}
+	return "unknown"
+}
+
+type Order struct {
+	Status  Status
+	History []Status
+	Items   map[string]Status
+	Prev    *Status
+	Price   money.Cents
+	Refund  string
+	Count   int
+}
+
+type OrderView struct {
+	Status  string
+	History []string
+	Items   map[string]string
+	Prev    *string
+	Price   string
+	Refund  money.Cents
+	Count   string
+}
+
+// :quickcopy
+func CopyOrderView(dst *OrderView, src *Order) {
+	dst.Status = statusName(src.Status)
+	dst.History = copySliceStringFromSliceStatus(src.History)
+	dst.Items = copyMapStringToStringFromMapStringToStatus(src.Items)
+	dst.Prev = func(src *Status) *string {
+		if src == nil {
+			return nil
+		}
+		dst := new(string)
+		*dst = statusName(*src)
+		return dst
+	}(src.Prev)
+	dst.Price = money.CentsToString(src.Price)
+	dst.Refund = func(v string) money.Cents {
+		r, _ := money.ParseCents(v)
+		return r
+	}(src.Refund)
+	dst.Count = fmt.Sprint(src.Count)
+}
+
+func TestConverter(t *testing.T) {
+	prev := StatusBanned
+	src := Order{
+		Status:  StatusActive,
+		History: []Status{StatusBanned, StatusActive},
+		Items:   map[string]Status{"a": StatusActive},
+		Prev:    &prev,
+		Price:   1205,
+		Refund:  "3.50",
+		Count:   7,
+	}
+
+	var dst OrderView
+	CopyOrderView(&dst, &src)
+
+	want := OrderView{
+		Status:  "active",
+		History: []string{"banned", "active"},
+		Items:   map[string]string{"a": "active"},
+		Price:   "12.05",
+		Refund:  350,
+		Count:   "7",
+	}
+	if dst.Prev == nil || *dst.Prev != "banned" {
+		t.Fatalf("Prev = %v, want banned", dst.Prev)
+	}
+	dst.Prev = nil
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got %+v, want %+v", dst, want)
+	}
+}
@@ -0,0 +1,26 @@
+package money
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Cents 以分为单位的金额
+type Cents int64
+
+// :quickcopy-converter
+func CentsToString(c Cents) string {
+	return fmt.Sprintf("%d.%02d", c/100, c%100)
+}
+
+// :quickcopy-converter
+func ParseCents(s string) (Cents, error) {
+	yuan, fen, _ := strings.Cut(s, ".")
+	y, err := strconv.ParseInt(yuan, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	f, _ := strconv.ParseInt(fen, 10, 64)
+	return Cents(y*100 + f), nil
+}
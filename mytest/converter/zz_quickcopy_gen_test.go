@@ -0,0 +1,27 @@
+// Code generated by quickcopy. DO NOT EDIT.
+
+package converter
+
+// copyMapStringToStringFromMapStringToStatus 是自动生成的 map 拷贝函数
+func copyMapStringToStringFromMapStringToStatus(src map[string]Status) map[string]string {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[string]string, len(src))
+	for k, v := range src {
+		dst[k] = statusName(v)
+	}
+	return dst
+}
+
+// copySliceStringFromSliceStatus 是自动生成的切片拷贝函数
+func copySliceStringFromSliceStatus(src []Status) []string {
+	if src == nil {
+		return nil
+	}
+	dst := make([]string, len(src))
+	for i := range src {
+		dst[i] = statusName(src[i])
+	}
+	return dst
+}
@@ -0,0 +1,117 @@
+package copyarray
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type Hash [16]byte
+
+type SrcPoint struct {
+	X, Y int
+}
+
+type DstPoint struct {
+	X, Y int64
+}
+
+type ArraySource struct {
+	Coords [3]int
+	Points [2]SrcPoint
+	Digest [4]byte
+	Tags   []string
+	Short  []int
+	ID     uuid.UUID
+	Raw    Hash
+	Matrix [2][2]int
+}
+
+type ArrayDest struct {
+	Coords [3]float64
+	Points [2]DstPoint
+	Digest []byte
+	Tags   [2]string
+	Short  [4]int64
+	ID     Hash
+	Raw    []byte
+	Matrix [2][2]int64
+}
+
+// :quickcopy
+func CopyArrays(dst *ArrayDest, src *ArraySource) {
+	dst.Coords = copyArr_3float64FromArr_3int(src.Coords)
+	dst.Points = copyArr_2DstPointFromArr_2SrcPoint(src.Points)
+	dst.Digest = copySlice_byteFromArr_4byte(src.Digest)
+	dst.Tags = copyArr_2stringFromSlice_string(src.Tags)
+	dst.Short = copyArr_4int64FromSlice_int(src.Short)
+	dst.ID = Hash(src.ID)
+	dst.Raw = copySlice_byteFromArr_16byte(src.Raw)
+	dst.Matrix = copyArr_2Arr_2int64FromArr_2Arr_2int(src.Matrix)
+}
+
+// :quickcopy
+func CopyCoords(dst *[3]int64, src *[3]int32) {
+	*dst = copyArr_3int64FromArr_3int32(*src)
+}
+
+func TestCopyArrays(t *testing.T) {
+	id := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	src := &ArraySource{
+		Coords: [3]int{1, 2, 3},
+		Points: [2]SrcPoint{{X: 1, Y: 2}, {X: 3, Y: 4}},
+		Digest: [4]byte{0xde, 0xad, 0xbe, 0xef},
+		Tags:   []string{"a", "b", "c"},
+		Short:  []int{5, 6},
+		ID:     id,
+		Raw:    Hash{1, 2, 3},
+		Matrix: [2][2]int{{1, 2}, {3, 4}},
+	}
+	dst := &ArrayDest{}
+
+	CopyArrays(dst, src)
+
+	if dst.Coords != [3]float64{1, 2, 3} {
+		t.Errorf("Coords: got %v", dst.Coords)
+	}
+	if dst.Points != [2]DstPoint{{X: 1, Y: 2}, {X: 3, Y: 4}} {
+		t.Errorf("Points: got %v", dst.Points)
+	}
+	if string(dst.Digest) != "\xde\xad\xbe\xef" {
+		t.Errorf("Digest: got %x", dst.Digest)
+	}
+	// 目标数组更短时截断
+	if dst.Tags != [2]string{"a", "b"} {
+		t.Errorf("Tags: got %v", dst.Tags)
+	}
+	// 目标数组更长时剩余元素为零值
+	if dst.Short != [4]int64{5, 6, 0, 0} {
+		t.Errorf("Short: got %v", dst.Short)
+	}
+	if dst.ID != Hash(id) {
+		t.Errorf("ID: got %x", dst.ID)
+	}
+	if len(dst.Raw) != 16 || dst.Raw[0] != 1 || dst.Raw[2] != 3 {
+		t.Errorf("Raw: got %v", dst.Raw)
+	}
+	if dst.Matrix != [2][2]int64{{1, 2}, {3, 4}} {
+		t.Errorf("Matrix: got %v", dst.Matrix)
+	}
+
+	// 切片是新分配的
+	dst.Digest[0] = 0
+	if src.Digest[0] != 0xde {
+		t.Errorf("Digest: dst shares storage with src")
+	}
+}
+
+func TestCopyCoords(t *testing.T) {
+	src := [3]int32{7, 8, 9}
+	var dst [3]int64
+
+	CopyCoords(&dst, &src)
+
+	if dst != [3]int64{7, 8, 9} {
+		t.Errorf("got %v", dst)
+	}
+}
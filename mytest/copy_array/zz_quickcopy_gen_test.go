@@ -0,0 +1,77 @@
+// Code generated by quickcopy. DO NOT EDIT.
+
+package copyarray
+
+// copyArr_2Arr_2int64FromArr_2Arr_2int 是自动生成的数组拷贝函数
+func copyArr_2Arr_2int64FromArr_2Arr_2int(src [2][2]int) (dst [2][2]int64) {
+	for i := 0; i < len(src) && i < len(dst); i++ {
+		dst[i] = copyArr_2int64FromArr_2int(src[i])
+	}
+	return dst
+}
+
+// copyArr_2DstPointFromArr_2SrcPoint 是自动生成的数组拷贝函数
+func copyArr_2DstPointFromArr_2SrcPoint(src [2]SrcPoint) (dst [2]DstPoint) {
+	for i := 0; i < len(src) && i < len(dst); i++ {
+		copyDstPointFromSrcPoint(&dst[i], &src[i])
+	}
+	return dst
+}
+
+// copyArr_2int64FromArr_2int 是自动生成的数组拷贝函数
+func copyArr_2int64FromArr_2int(src [2]int) (dst [2]int64) {
+	for i := 0; i < len(src) && i < len(dst); i++ {
+		dst[i] = int64(src[i])
+	}
+	return dst
+}
+
+// copyArr_2stringFromSlice_string 是自动生成的数组拷贝函数
+func copyArr_2stringFromSlice_string(src []string) (dst [2]string) {
+	copy(dst[:], src[:])
+	return dst
+}
+
+// copyArr_3float64FromArr_3int 是自动生成的数组拷贝函数
+func copyArr_3float64FromArr_3int(src [3]int) (dst [3]float64) {
+	for i := 0; i < len(src) && i < len(dst); i++ {
+		dst[i] = float64(src[i])
+	}
+	return dst
+}
+
+// copyArr_3int64FromArr_3int32 是自动生成的数组拷贝函数
+func copyArr_3int64FromArr_3int32(src [3]int32) (dst [3]int64) {
+	for i := 0; i < len(src) && i < len(dst); i++ {
+		dst[i] = int64(src[i])
+	}
+	return dst
+}
+
+// copyArr_4int64FromSlice_int 是自动生成的数组拷贝函数
+func copyArr_4int64FromSlice_int(src []int) (dst [4]int64) {
+	for i := 0; i < len(src) && i < len(dst); i++ {
+		dst[i] = int64(src[i])
+	}
+	return dst
+}
+
+// copyDstPointFromSrcPoint 是一个自动生成的拷贝函数
+func copyDstPointFromSrcPoint(dst *DstPoint, src *SrcPoint) {
+	dst.X = int64(src.X)
+	dst.Y = int64(src.Y)
+}
+
+// copySlice_byteFromArr_16byte 是自动生成的数组拷贝函数
+func copySlice_byteFromArr_16byte(src [16]byte) []byte {
+	dst := make([]byte, len(src))
+	copy(dst[:], src[:])
+	return dst
+}
+
+// copySlice_byteFromArr_4byte 是自动生成的数组拷贝函数
+func copySlice_byteFromArr_4byte(src [4]byte) []byte {
+	dst := make([]byte, len(src))
+	copy(dst[:], src[:])
+	return dst
+}
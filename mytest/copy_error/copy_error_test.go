@@ -0,0 +1,204 @@
+package copyerror
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type AddressReq struct {
+	Zip string
+}
+
+type Address struct {
+	Zip int
+}
+
+// 来自外部请求的数据，全部是字符串
+type UserReq struct {
+	Name      string
+	Age       string
+	Score     string
+	Birthday  string
+	Address   AddressReq
+	Phones    []string
+	Limits    map[string]string
+	Backup    *AddressReq
+	Nick      *string
+	CreatedAt string
+}
+
+type User struct {
+	Name      string
+	Age       int
+	Score     float64
+	Birthday  time.Time
+	Address   Address
+	Phones    []int64
+	Limits    map[string]int
+	Backup    *Address
+	Nick      *string
+	CreatedAt string
+}
+
+// :quickcopy
+func CopyUser(dst *User, src *UserReq) error {
+	var err error
+	dst.Name = src.Name
+	if dst.Age, err = strconv.Atoi(src.Age); err != nil {
+		return fmt.Errorf("Age: %w", err)
+	}
+	if dst.Score, err = func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	}(src.Score); err != nil {
+		return fmt.Errorf("Score: %w", err)
+	}
+	if dst.Birthday, err = func(s string) (time.Time, error) {
+		return time.Parse(time.RFC3339, s)
+	}(src.Birthday); err != nil {
+		return fmt.Errorf("Birthday: %w", err)
+	}
+	if err = copyAddressFromAddressReqErr(&dst.Address, &src.Address); err != nil {
+		return fmt.Errorf("Address.%w", err)
+	}
+	if dst.Phones, err = copySliceInt64FromSliceStringErr(src.Phones); err != nil {
+		return fmt.Errorf("Phones%w", err)
+	}
+	if dst.Limits, err = copyMapStringToIntFromMapStringToStringErr(src.Limits); err != nil {
+		return fmt.Errorf("Limits%w", err)
+	}
+	if dst.Backup, err = func(src *AddressReq) (dst *Address, err error) {
+		if src == nil {
+			return nil, nil
+		}
+		dst = new(Address)
+		err = copyAddressFromAddressReqErr(dst, src)
+		return dst, err
+	}(src.Backup); err != nil {
+		return fmt.Errorf("Backup.%w", err)
+	}
+	dst.Nick = src.Nick
+	dst.CreatedAt = src.CreatedAt
+	return nil
+}
+
+// :quickcopy --join-errors
+func CopyUserAll(dst *User, src *UserReq) error {
+	var err error
+	var errs []error
+	dst.Name = src.Name
+	if dst.Age, err = strconv.Atoi(src.Age); err != nil {
+		errs = appendCopyErrors(errs, "Age: ", err)
+	}
+	if dst.Score, err = func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	}(src.Score); err != nil {
+		errs = appendCopyErrors(errs, "Score: ", err)
+	}
+	if dst.Birthday, err = func(s string) (time.Time, error) {
+		return time.Parse(time.RFC3339, s)
+	}(src.Birthday); err != nil {
+		errs = appendCopyErrors(errs, "Birthday: ", err)
+	}
+	if err = copyAddressFromAddressReqErrs(&dst.Address, &src.Address); err != nil {
+		errs = appendCopyErrors(errs, "Address.", err)
+	}
+	if dst.Phones, err = copySliceInt64FromSliceStringErrs(src.Phones); err != nil {
+		errs = appendCopyErrors(errs, "Phones", err)
+	}
+	if dst.Limits, err = copyMapStringToIntFromMapStringToStringErrs(src.Limits); err != nil {
+		errs = appendCopyErrors(errs, "Limits", err)
+	}
+	if dst.Backup, err = func(src *AddressReq) (dst *Address, err error) {
+		if src == nil {
+			return nil, nil
+		}
+		dst = new(Address)
+		err = copyAddressFromAddressReqErrs(dst, src)
+		return dst, err
+	}(src.Backup); err != nil {
+		errs = appendCopyErrors(errs, "Backup.", err)
+	}
+	dst.Nick = src.Nick
+	dst.CreatedAt = src.CreatedAt
+	return errors.Join(errs...)
+}
+
+func validReq() UserReq {
+	return UserReq{
+		Name:     "Alice",
+		Age:      "30",
+		Score:    "99.5",
+		Birthday: "2000-01-02T03:04:05Z",
+		Address:  AddressReq{Zip: "100000"},
+		Phones:   []string{"13800000000"},
+		Limits:   map[string]string{"daily": "10"},
+		Backup:   &AddressReq{Zip: "200000"},
+	}
+}
+
+func TestCopyUser(t *testing.T) {
+	src := validReq()
+	var dst User
+	if err := CopyUser(&dst, &src); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Age != 30 || dst.Score != 99.5 || dst.Address.Zip != 100000 || dst.Backup.Zip != 200000 ||
+		dst.Phones[0] != 13800000000 || dst.Limits["daily"] != 10 || dst.Birthday.Year() != 2000 {
+		t.Errorf("unexpected result: %+v", dst)
+	}
+}
+
+func TestCopyUserError(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		mod  func(*UserReq)
+		path string
+	}{
+		{"field", func(r *UserReq) { r.Age = "abc" }, "Age: "},
+		{"time", func(r *UserReq) { r.Birthday = "yesterday" }, "Birthday: "},
+		{"nested", func(r *UserReq) { r.Address.Zip = "x" }, "Address.Zip: "},
+		{"slice", func(r *UserReq) { r.Phones = []string{"1", "two"} }, "Phones[1]: "},
+		{"map", func(r *UserReq) { r.Limits = map[string]string{"daily": "ten"} }, "Limits[daily]: "},
+		{"pointer", func(r *UserReq) { r.Backup.Zip = "y" }, "Backup.Zip: "},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			src := validReq()
+			tc.mod(&src)
+			var dst User
+			err := CopyUser(&dst, &src)
+			if err == nil || !strings.HasPrefix(err.Error(), tc.path) {
+				t.Fatalf("err = %v, want prefix %q", err, tc.path)
+			}
+			var numErr *strconv.NumError
+			var timeErr *time.ParseError
+			if !errors.As(err, &numErr) && !errors.As(err, &timeErr) {
+				t.Errorf("err %v does not wrap the conversion error", err)
+			}
+		})
+	}
+}
+
+func TestCopyUserAll(t *testing.T) {
+	src := validReq()
+	src.Age = "abc"
+	src.Phones = []string{"x", "1", "y"}
+
+	var dst User
+	err := CopyUserAll(&dst, &src)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{"Age: ", "Phones[0]: ", "Phones[2]: "} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want it to contain %q", err, want)
+		}
+	}
+	// 其他字段照常拷贝
+	if dst.Score != 99.5 || dst.Phones[1] != 1 {
+		t.Errorf("unexpected result: %+v", dst)
+	}
+}
@@ -0,0 +1,112 @@
+// Code generated by quickcopy. DO NOT EDIT.
+
+package copyerror
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// appendCopyErrors 是自动生成的函数，给 err 中的每个错误加上路径前缀后追加到 errs
+func appendCopyErrors(errs []error, prefix string, err error) []error {
+	if joined, ok := err.(interface {
+		Unwrap() []error
+	}); ok {
+		for _, e := range joined.Unwrap() {
+			errs = appendCopyErrors(errs, prefix, e)
+		}
+		return errs
+	}
+	return append(errs, fmt.Errorf("%s%w", prefix, err))
+}
+
+// copyAddressFromAddressReqErr 是一个自动生成的拷贝函数
+func copyAddressFromAddressReqErr(dst *Address, src *AddressReq) error {
+	var err error
+	if dst.Zip, err = strconv.Atoi(src.Zip); err != nil {
+		return fmt.Errorf("Zip: %w", err)
+	}
+	return nil
+}
+
+// copyAddressFromAddressReqErrs 是一个自动生成的拷贝函数
+func copyAddressFromAddressReqErrs(dst *Address, src *AddressReq) error {
+	var err error
+	var errs []error
+	if dst.Zip, err = strconv.Atoi(src.Zip); err != nil {
+		errs = appendCopyErrors(errs, "Zip: ", err)
+	}
+	return errors.Join(errs...)
+}
+
+// copyMapStringToIntFromMapStringToStringErr 是自动生成的 map 拷贝函数
+func copyMapStringToIntFromMapStringToStringErr(src map[string]string) (map[string]int, error) {
+	if src == nil {
+		return nil, nil
+	}
+	dst := make(map[string]int, len(src))
+	var err error
+	for k, v := range src {
+		var dv int
+		if dv, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("[%v]: %w", k, err)
+		}
+		dst[k] = dv
+	}
+	return dst, nil
+}
+
+// copyMapStringToIntFromMapStringToStringErrs 是自动生成的 map 拷贝函数
+func copyMapStringToIntFromMapStringToStringErrs(src map[string]string) (map[string]int, error) {
+	if src == nil {
+		return nil, nil
+	}
+	dst := make(map[string]int, len(src))
+	var err error
+	var errs []error
+	for k, v := range src {
+		var dv int
+		if dv, err = strconv.Atoi(v); err != nil {
+			errs = appendCopyErrors(errs, fmt.Sprintf("[%v]: ", k), err)
+			continue
+		}
+		dst[k] = dv
+	}
+	return dst, errors.Join(errs...)
+}
+
+// copySliceInt64FromSliceStringErr 是自动生成的切片拷贝函数
+func copySliceInt64FromSliceStringErr(src []string) ([]int64, error) {
+	if src == nil {
+		return nil, nil
+	}
+	dst := make([]int64, len(src))
+	var err error
+	for i := range src {
+		if dst[i], err = func(s string) (int64, error) {
+			return strconv.ParseInt(s, 10, 64)
+		}(src[i]); err != nil {
+			return nil, fmt.Errorf("[%d]: %w", i, err)
+		}
+	}
+	return dst, nil
+}
+
+// copySliceInt64FromSliceStringErrs 是自动生成的切片拷贝函数
+func copySliceInt64FromSliceStringErrs(src []string) ([]int64, error) {
+	if src == nil {
+		return nil, nil
+	}
+	dst := make([]int64, len(src))
+	var err error
+	var errs []error
+	for i := range src {
+		if dst[i], err = func(s string) (int64, error) {
+			return strconv.ParseInt(s, 10, 64)
+		}(src[i]); err != nil {
+			errs = appendCopyErrors(errs, fmt.Sprintf("[%d]: ", i), err)
+		}
+	}
+	return dst, errors.Join(errs...)
+}
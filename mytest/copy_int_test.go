@@ -5,30 +5,25 @@ import (
 )
 
 type copy1 struct {
-	A	int8
-	B	int16
-	C	int32
-	D	int64
+	A int8
+	B int16
+	C int32
+	D int64
 }
 
 type copy2 struct {
-	A	int8
-	B	int16
-	C	int32
-	D	int64
+	A int8
+	B int16
+	C int32
+	D int64
 }
 
 // :quickcopy
 func QuickCopy(dst *copy1, src *copy2) {
-
 	dst.A = src.A
-
 	dst.B = src.B
-
 	dst.C = src.C
-
-	dst.
-		D = src.D
+	dst.D = src.D
 }
 func TestQuickCopy(t *testing.T) {
 	src := &copy2{A: 1, B: 2, C: 3, D: 4}
@@ -0,0 +1,107 @@
+package copymap
+
+import (
+	"testing"
+)
+
+type SrcItem struct {
+	ID   int
+	Name string
+}
+
+type DstItem struct {
+	ID   int64
+	Name string
+}
+
+type MapSource struct {
+	Labels map[string]string
+	Items  map[string]SrcItem
+	Counts map[int]int32
+	Nested map[string]map[string]SrcItem
+	Groups map[string][]SrcItem
+	Ptrs   map[string]*SrcItem
+	Empty  map[string]SrcItem
+}
+
+type MapDest struct {
+	Labels map[string]string
+	Items  map[string]DstItem
+	Counts map[string]int64
+	Nested map[string]map[string]DstItem
+	Groups map[string][]DstItem
+	Ptrs   map[string]*DstItem
+	Empty  map[string]DstItem
+}
+
+// :quickcopy
+func CopyMaps(dst *MapDest, src *MapSource) {
+	dst.Labels = src.Labels
+	dst.Items = copyMapStringToDstItemFromMapStringToSrcItem(src.Items)
+	dst.Counts = copyMapStringToInt64FromMapIntToInt32(src.Counts)
+	dst.Nested = copyMapStringToMap_stringDstItemFromMapStringToMap_stringSrcItem(src.Nested)
+	dst.Groups = copyMapStringToSlice_DstItemFromMapStringToSlice_SrcItem(src.Groups)
+	dst.Ptrs = copyMapStringToPtr_DstItemFromMapStringToPtr_SrcItem(src.Ptrs)
+	dst.Empty = copyMapStringToDstItemFromMapStringToSrcItem(src.Empty)
+}
+
+// :quickcopy
+func CopyScores(dst *map[string]int64, src *map[string]int) {
+	*dst = copyMapStringToInt64FromMapStringToInt(*src)
+}
+
+func TestCopyMaps(t *testing.T) {
+	src := &MapSource{
+		Labels: map[string]string{"env": "prod"},
+		Items:  map[string]SrcItem{"a": {ID: 1, Name: "A"}},
+		Counts: map[int]int32{7: 70},
+		Nested: map[string]map[string]SrcItem{"x": {"y": {ID: 2, Name: "Y"}}},
+		Groups: map[string][]SrcItem{"g": {{ID: 3, Name: "G"}}},
+		Ptrs:   map[string]*SrcItem{"p": {ID: 4, Name: "P"}, "nil": nil},
+	}
+	dst := &MapDest{}
+
+	CopyMaps(dst, src)
+
+	if dst.Labels["env"] != "prod" {
+		t.Errorf("Labels: got %v", dst.Labels)
+	}
+	if got := dst.Items["a"]; got.ID != 1 || got.Name != "A" {
+		t.Errorf("Items: got %+v", got)
+	}
+	if dst.Counts["7"] != 70 {
+		t.Errorf("Counts: got %v", dst.Counts)
+	}
+	if got := dst.Nested["x"]["y"]; got.ID != 2 || got.Name != "Y" {
+		t.Errorf("Nested: got %+v", got)
+	}
+	if got := dst.Groups["g"]; len(got) != 1 || got[0].ID != 3 {
+		t.Errorf("Groups: got %+v", got)
+	}
+	if got := dst.Ptrs["p"]; got == nil || got.ID != 4 || got.Name != "P" {
+		t.Errorf("Ptrs: got %+v", got)
+	}
+	if got, ok := dst.Ptrs["nil"]; !ok || got != nil {
+		t.Errorf("Ptrs[nil]: got %+v, %v", got, ok)
+	}
+	if dst.Empty != nil {
+		t.Errorf("Empty: nil map should stay nil, got %v", dst.Empty)
+	}
+
+	// 修改目标 map 不影响源 map
+	dst.Items["b"] = DstItem{ID: 9}
+	if _, ok := src.Items["b"]; ok {
+		t.Errorf("Items: dst shares storage with src")
+	}
+}
+
+func TestCopyScores(t *testing.T) {
+	src := map[string]int{"alice": 90}
+	var dst map[string]int64
+
+	CopyScores(&dst, &src)
+
+	if dst["alice"] != 90 {
+		t.Errorf("got %v", dst)
+	}
+}
@@ -0,0 +1,104 @@
+// Code generated by quickcopy. DO NOT EDIT.
+
+package copymap
+
+import "fmt"
+
+// copyDstItemFromSrcItem 是一个自动生成的拷贝函数
+func copyDstItemFromSrcItem(dst *DstItem, src *SrcItem) {
+	dst.ID = int64(src.ID)
+	dst.Name = src.Name
+}
+
+// copyMapStringToDstItemFromMapStringToSrcItem 是自动生成的 map 拷贝函数
+func copyMapStringToDstItemFromMapStringToSrcItem(src map[string]SrcItem) map[string]DstItem {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[string]DstItem, len(src))
+	for k, v := range src {
+		var dv DstItem
+		copyDstItemFromSrcItem(&dv, &v)
+		dst[k] = dv
+	}
+	return dst
+}
+
+// copyMapStringToInt64FromMapIntToInt32 是自动生成的 map 拷贝函数
+func copyMapStringToInt64FromMapIntToInt32(src map[int]int32) map[string]int64 {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[string]int64, len(src))
+	for k, v := range src {
+		dst[fmt.Sprint(k)] = int64(v)
+	}
+	return dst
+}
+
+// copyMapStringToInt64FromMapStringToInt 是自动生成的 map 拷贝函数
+func copyMapStringToInt64FromMapStringToInt(src map[string]int) map[string]int64 {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[string]int64, len(src))
+	for k, v := range src {
+		dst[k] = int64(v)
+	}
+	return dst
+}
+
+// copyMapStringToMap_stringDstItemFromMapStringToMap_stringSrcItem 是自动生成的 map 拷贝函数
+func copyMapStringToMap_stringDstItemFromMapStringToMap_stringSrcItem(src map[string]map[string]SrcItem) map[string]map[string]DstItem {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[string]map[string]DstItem, len(src))
+	for k, v := range src {
+		dst[k] = copyMapStringToDstItemFromMapStringToSrcItem(v)
+	}
+	return dst
+}
+
+// copyMapStringToPtr_DstItemFromMapStringToPtr_SrcItem 是自动生成的 map 拷贝函数
+func copyMapStringToPtr_DstItemFromMapStringToPtr_SrcItem(src map[string]*SrcItem) map[string]*DstItem {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[string]*DstItem, len(src))
+	for k, v := range src {
+		dst[k] = func(src *SrcItem) *DstItem {
+			if src == nil {
+				return nil
+			}
+			dst := new(DstItem)
+			copyDstItemFromSrcItem(dst, src)
+			return dst
+		}(v)
+	}
+	return dst
+}
+
+// copyMapStringToSlice_DstItemFromMapStringToSlice_SrcItem 是自动生成的 map 拷贝函数
+func copyMapStringToSlice_DstItemFromMapStringToSlice_SrcItem(src map[string][]SrcItem) map[string][]DstItem {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[string][]DstItem, len(src))
+	for k, v := range src {
+		dst[k] = copySliceDstItemFromSliceSrcItem(v)
+	}
+	return dst
+}
+
+// copySliceDstItemFromSliceSrcItem 是自动生成的切片拷贝函数
+func copySliceDstItemFromSliceSrcItem(src []SrcItem) []DstItem {
+	if src == nil {
+		return nil
+	}
+	dst := make([]DstItem, len(src))
+	for i := range src {
+		copyDstItemFromSrcItem(&dst[i], &src[i])
+	}
+	return dst
+}
@@ -0,0 +1,204 @@
+package copypointer
+
+import "testing"
+
+type SrcAddr struct {
+	City string
+}
+
+type DstAddr struct {
+	City string
+}
+
+// 持久化模型，可以为空的列使用指针
+type UserRow struct {
+	Name    *string
+	Age     *int
+	Score   *float32
+	Nick    *string
+	Address *SrcAddr
+	Deep    **int
+	Tags    []*string
+}
+
+// API 模型使用普通值
+type UserView struct {
+	Name    string
+	Age     int64
+	Score   float64
+	Nick    string
+	Address DstAddr
+	Deep    int
+	Tags    []string
+}
+
+// :quickcopy --default=Nick:"anonymous"
+func CopyUserView(dst *UserView, src *UserRow) {
+	dst.Name = func(src *string) (dst string) {
+		if src == nil {
+			return
+		}
+		dst = *src
+		return dst
+	}(src.Name)
+	dst.Age = func(src *int) (dst int64) {
+		if src == nil {
+			return
+		}
+		dst = int64(*src)
+		return dst
+	}(src.Age)
+	dst.Score = func(src *float32) (dst float64) {
+		if src == nil {
+			return
+		}
+		dst = float64(*src)
+		return dst
+	}(src.Score)
+	dst.Nick = func(src *string) (dst string) {
+		if src == nil {
+			return "anonymous"
+		}
+		dst = *src
+		return dst
+	}(src.Nick)
+	dst.Address = func(src *SrcAddr) (dst DstAddr) {
+		if src == nil {
+			return
+		}
+		copyDstAddrFromSrcAddr(&dst, src)
+		return dst
+	}(src.Address)
+	dst.Deep = func(src **int) (dst int) {
+		if src == nil {
+			return
+		}
+		dst = func(src *int) (dst int) {
+			if src == nil {
+				return
+			}
+			dst = *src
+			return dst
+		}(*src)
+		return dst
+	}(src.Deep)
+	dst.Tags = copySliceStringFromSlicePtr_string(src.Tags)
+}
+
+// :quickcopy --allow-narrow
+func CopyUserRow(dst *UserRow, src *UserView) {
+	dst.Name = func(src string) *string {
+		dst := new(string)
+		*dst = src
+		return dst
+	}(src.Name)
+	dst.Age = func(src int64) *int {
+		dst := new(int)
+		*dst = int(src)
+		return dst
+	}(src.Age)
+	dst.Score = func(src float64) *float32 {
+		dst := new(float32)
+		*dst = float32(src)
+		return dst
+	}(src.Score)
+	dst.Nick = func(src string) *string {
+		dst := new(string)
+		*dst = src
+		return dst
+	}(src.Nick)
+	dst.Address = func(src DstAddr) *SrcAddr {
+		dst := new(SrcAddr)
+		copySrcAddrFromDstAddrNarrow(dst, &src)
+		return dst
+	}(src.Address)
+	dst.Deep = func(src int) **int {
+		dst := new(*int)
+		*dst = func(src int) *int {
+			dst := new(int)
+			*dst = src
+			return dst
+		}(src)
+		return dst
+	}(src.Deep)
+	dst.Tags = copySlicePtr_stringFromSliceString(src.Tags)
+}
+
+func TestCopyUserView(t *testing.T) {
+	name, age, score, tag := "alice", 18, float32(1.5), "go"
+	deep := new(int)
+	*deep = 7
+	src := &UserRow{
+		Name:    &name,
+		Age:     &age,
+		Score:   &score,
+		Address: &SrcAddr{City: "Beijing"},
+		Deep:    &deep,
+		Tags:    []*string{&tag, nil},
+	}
+	dst := &UserView{}
+
+	CopyUserView(dst, src)
+
+	if dst.Name != "alice" || dst.Age != 18 || dst.Score != 1.5 {
+		t.Errorf("got %+v", dst)
+	}
+	if dst.Nick != "anonymous" {
+		t.Errorf("Nick: nil pointer should use default, got %q", dst.Nick)
+	}
+	if dst.Address.City != "Beijing" {
+		t.Errorf("Address: got %+v", dst.Address)
+	}
+	if dst.Deep != 7 {
+		t.Errorf("Deep: got %d", dst.Deep)
+	}
+	if len(dst.Tags) != 2 || dst.Tags[0] != "go" || dst.Tags[1] != "" {
+		t.Errorf("Tags: got %q", dst.Tags)
+	}
+
+	// nil 指针得到零值
+	dst = &UserView{Name: "old", Age: 1}
+	CopyUserView(dst, &UserRow{})
+	if dst.Name != "" || dst.Age != 0 || dst.Deep != 0 {
+		t.Errorf("nil pointers should become zero values, got %+v", dst)
+	}
+}
+
+func TestCopyUserRow(t *testing.T) {
+	src := &UserView{
+		Name:    "bob",
+		Age:     20,
+		Score:   2.5,
+		Address: DstAddr{City: "Shanghai"},
+		Deep:    3,
+		Tags:    []string{"a"},
+	}
+	dst := &UserRow{}
+
+	CopyUserRow(dst, src)
+
+	if dst.Name == nil || *dst.Name != "bob" {
+		t.Errorf("Name: got %v", dst.Name)
+	}
+	if dst.Age == nil || *dst.Age != 20 {
+		t.Errorf("Age: got %v", dst.Age)
+	}
+	if dst.Score == nil || *dst.Score != 2.5 {
+		t.Errorf("Score: got %v", dst.Score)
+	}
+	if dst.Address == nil || dst.Address.City != "Shanghai" {
+		t.Errorf("Address: got %v", dst.Address)
+	}
+	if dst.Deep == nil || *dst.Deep == nil || **dst.Deep != 3 {
+		t.Errorf("Deep: got %v", dst.Deep)
+	}
+	if len(dst.Tags) != 1 || *dst.Tags[0] != "a" {
+		t.Errorf("Tags: got %v", dst.Tags)
+	}
+
+	// 修改目标不影响源
+	*dst.Name = "changed"
+	if src.Name != "bob" {
+		t.Errorf("Name: dst shares storage with src")
+	}
+}
@@ -0,0 +1,47 @@
+// Code generated by quickcopy. DO NOT EDIT.
+
+package copypointer
+
+// copyDstAddrFromSrcAddr 是一个自动生成的拷贝函数
+func copyDstAddrFromSrcAddr(dst *DstAddr, src *SrcAddr) {
+	dst.City = src.City
+}
+
+// copySlicePtr_stringFromSliceString 是自动生成的切片拷贝函数
+func copySlicePtr_stringFromSliceString(src []string) []*string {
+	if src == nil {
+		return nil
+	}
+	dst := make([]*string, len(src))
+	for i := range src {
+		dst[i] = func(src string) *string {
+			dst := new(string)
+			*dst = src
+			return dst
+		}(src[i])
+	}
+	return dst
+}
+
+// copySliceStringFromSlicePtr_string 是自动生成的切片拷贝函数
+func copySliceStringFromSlicePtr_string(src []*string) []string {
+	if src == nil {
+		return nil
+	}
+	dst := make([]string, len(src))
+	for i := range src {
+		dst[i] = func(src *string) (dst string) {
+			if src == nil {
+				return
+			}
+			dst = *src
+			return dst
+		}(src[i])
+	}
+	return dst
+}
+
+// copySrcAddrFromDstAddrNarrow 是一个自动生成的拷贝函数
+func copySrcAddrFromDstAddrNarrow(dst *SrcAddr, src *DstAddr) {
+	dst.City = src.City
+}
@@ -0,0 +1,21 @@
+// Code generated by quickcopy. DO NOT EDIT.
+
+package copyslicemini
+
+// copyDestItemFromSourceItem 是一个自动生成的拷贝函数
+func copyDestItemFromSourceItem(dst *DestItem, src *SourceItem) {
+	dst.ID = int64(src.ID)
+	dst.Name = src.Name
+}
+
+// copySliceDestItemFromSliceSourceItem 是自动生成的切片拷贝函数
+func copySliceDestItemFromSliceSourceItem(src []SourceItem) []DestItem {
+	if src == nil {
+		return nil
+	}
+	dst := make([]DestItem, len(src))
+	for i := range src {
+		copyDestItemFromSourceItem(&dst[i], &src[i])
+	}
+	return dst
+}
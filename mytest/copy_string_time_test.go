@@ -15,7 +15,6 @@ type copy4 struct {
 
 // :quickcopy
 func QuickCopy3(dst *copy4, src *copy3) {
-
 	dst.T = func(s string) time.Time {
 		t, _ := time.Parse(time.RFC3339, s)
 		return t
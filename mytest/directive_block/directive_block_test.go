@@ -0,0 +1,46 @@
+package directive_block
+
+import (
+	"testing"
+)
+
+type User struct {
+	FirstName string
+	LastName  string
+	UserID    int
+	Email     string
+	Password  string
+}
+
+type UserView struct {
+	FullName string
+	ID       int
+	Email    string
+	Note     string
+	Avatar   string
+}
+
+// CopyUserView 的指令写成多行，每行一条规则，选项可以单独一行
+// :quickcopy
+// --strict --strict-source
+// FullName = src.FirstName + " " + src.LastName
+// ID = UserID
+// Note = "a, b --c"
+// -Avatar, _ = Password
+func CopyUserView(dst *UserView, src *User) {
+	dst.FullName = src.FirstName + " " + src.LastName
+	dst.ID = src.UserID
+	dst.Note = "a, b --c"
+	dst.Email = src.Email
+}
+
+func TestDirectiveBlock(t *testing.T) {
+	src := User{FirstName: "Alice", LastName: "Smith", UserID: 7, Email: "a@example.com", Password: "secret"}
+	var dst UserView
+	CopyUserView(&dst, &src)
+
+	want := UserView{FullName: "Alice Smith", ID: 7, Email: "a@example.com", Note: "a, b --c"}
+	if dst != want {
+		t.Errorf("got %+v, want %+v", dst, want)
+	}
+}
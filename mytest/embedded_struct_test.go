@@ -8,54 +8,47 @@ import (
 
 // 基础用户信息
 type UserBase struct {
-	ID		int
-	CreatedAt	time.Time
-	UpdatedAt	time.Time
+	ID        int
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 // 源结构体：完整的用户信息
 type UserSource struct {
-	UserBase	// 嵌入基础用户信息
-	Name		string
-	Age		int
-	Email		string
+	UserBase // 嵌入基础用户信息
+	Name     string
+	Age      int
+	Email    string
 }
 
 // 目标结构体：用户视图
 type UserView struct {
-	UserBase	// 嵌入基础用户信息
-	Name		string
-	Age		string	// 类型转换：int -> string
-	Contact		string	// 映射自 Email
+	UserBase // 嵌入基础用户信息
+	Name     string
+	Age      string // 类型转换：int -> string
+	Contact  string // 映射自 Email
 }
 
 // :quickcopy Contact=Email
 func CopyToUserView(dst *UserView, src *UserSource) {
-
 	dst.Contact = src.Email
-
 	dst.ID = src.ID
-
 	dst.CreatedAt = src.CreatedAt
-
 	dst.UpdatedAt = src.UpdatedAt
-
 	dst.Name = src.Name
-	dst.Age = fmt.Sprint(src.
-		Age,
-	)
+	dst.Age = fmt.Sprint(src.Age)
 }
 
 func TestEmbeddedStructCopy(t *testing.T) {
 	src := &UserSource{
 		UserBase: UserBase{
-			ID:		1,
-			CreatedAt:	time.Now(),
-			UpdatedAt:	time.Now(),
+			ID:        1,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
 		},
-		Name:	"张三",
-		Age:	25,
-		Email:	"zhangsan@example.com",
+		Name:  "张三",
+		Age:   25,
+		Email: "zhangsan@example.com",
 	}
 
 	dst := &UserView{}
@@ -83,3 +76,54 @@ func TestEmbeddedStructCopy(t *testing.T) {
 		t.Errorf("Email not mapped correctly to Contact")
 	}
 }
+
+// 内嵌结构体指针：源为 nil 时不拷贝，目标为 nil 时先分配
+type AuditInfo struct {
+	CreatedBy string
+	Version   int
+}
+
+type AuditView struct {
+	CreatedBy string
+	Rev       int64
+}
+
+type DocSource struct {
+	*AuditInfo
+	Title string
+}
+
+type DocView struct {
+	*AuditView
+	Title string
+}
+
+// :quickcopy Rev = Version
+func CopyToDocView(dst *DocView, src *DocSource) {
+	if src.AuditInfo != nil {
+		if dst.AuditView == nil {
+			dst.AuditView = new(AuditView)
+		}
+		dst.Rev = int64(src.Version)
+	}
+	if src.AuditInfo != nil {
+		if dst.AuditView == nil {
+			dst.AuditView = new(AuditView)
+		}
+		dst.CreatedBy = src.CreatedBy
+	}
+	dst.Title = src.Title
+}
+
+func TestEmbeddedPointerCopy(t *testing.T) {
+	var dst DocView
+	CopyToDocView(&dst, &DocSource{Title: "draft"})
+	if dst.Title != "draft" || dst.AuditView != nil {
+		t.Errorf("nil embedded pointer should not be copied, got %+v", dst)
+	}
+
+	CopyToDocView(&dst, &DocSource{AuditInfo: &AuditInfo{CreatedBy: "alice", Version: 3}, Title: "final"})
+	if dst.AuditView == nil || dst.CreatedBy != "alice" || dst.Rev != 3 || dst.Title != "final" {
+		t.Errorf("embedded pointer fields not copied, got %+v", dst)
+	}
+}
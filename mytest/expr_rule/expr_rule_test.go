@@ -0,0 +1,67 @@
+package expr_rule
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Status int
+
+const StatusActive Status = 1
+
+type User struct {
+	FirstName string
+	LastName  string
+	Email     string
+	Tags      []string
+}
+
+type Meta struct {
+	Source  string
+	Version int
+}
+
+type UserView struct {
+	FullName string
+	Email    string
+	Tags     string
+	Status   Status
+	Active   bool
+	Meta     *Meta
+}
+
+// :quickcopy FullName = src.FirstName + " " + src.LastName, Tags = strings.Join(src.Tags, ", "), Status = StatusActive, Active = true, Meta.Source = "api", Meta.Version = 2
+func CopyUserView(dst *UserView, src *User) {
+	dst.FullName = src.FirstName + " " + src.LastName
+	dst.Tags = strings.Join(src.Tags, ", ")
+	dst.Status = StatusActive
+	dst.Active = true
+	if dst.Meta == nil {
+		dst.Meta = new(Meta)
+	}
+	dst.Meta.Source = "api"
+	if dst.Meta == nil {
+		dst.Meta = new(Meta)
+	}
+	dst.Meta.Version = 2
+	dst.Email = src.Email
+}
+
+func TestExprRule(t *testing.T) {
+	src := User{FirstName: "Alice", LastName: "Smith", Email: "alice@example.com", Tags: []string{"a", "b"}}
+	var dst UserView
+	CopyUserView(&dst, &src)
+
+	want := UserView{
+		FullName: "Alice Smith",
+		Email:    "alice@example.com",
+		Tags:     strings.Join(src.Tags, ", "),
+		Status:   StatusActive,
+		Active:   true,
+		Meta:     &Meta{Source: "api", Version: 2},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got %+v, want %+v", dst, want)
+	}
+}
@@ -0,0 +1,32 @@
+package expr_rule
+
+import (
+	. "strconv"
+	str "strings"
+	"testing"
+)
+
+type Account struct {
+	First string
+	Last  string
+	ID    int
+}
+
+type AccountView struct {
+	Full string
+	ID   string
+}
+
+// 生成到单独的文件时，表达式里的导入别名和点导入按生成文件的导入改写
+// :quickcopy Full = str.ToUpper(src.First) + " " + src.Last, ID = Itoa(src.ID)
+var _ func(dst *AccountView, src *Account) = CopyAccountView
+
+func TestExprRuleImports(t *testing.T) {
+	var dst AccountView
+	CopyAccountView(&dst, &Account{First: "alice", Last: "Smith", ID: 7})
+
+	want := AccountView{Full: str.ToUpper("alice") + " Smith", ID: Itoa(7)}
+	if dst != want {
+		t.Errorf("got %+v, want %+v", dst, want)
+	}
+}
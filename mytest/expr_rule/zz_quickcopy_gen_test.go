@@ -0,0 +1,14 @@
+// Code generated by quickcopy. DO NOT EDIT.
+
+package expr_rule
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CopyAccountView 是一个自动生成的拷贝函数
+func CopyAccountView(dst *AccountView, src *Account) {
+	dst.Full = strings.ToUpper(src.First) + " " + src.Last
+	dst.ID = strconv.Itoa(src.ID)
+}
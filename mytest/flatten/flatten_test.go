@@ -0,0 +1,132 @@
+package flatten
+
+import (
+	"reflect"
+	"testing"
+)
+
+type Geo struct {
+	Lat float64
+	Lng float64
+}
+
+type Address struct {
+	City   string
+	Street string
+	Geo    *Geo
+}
+
+type Order struct {
+	ID      int
+	Address *Address
+	Billing Address
+}
+
+// 扁平的读模型
+type OrderRow struct {
+	ID            int
+	AddressCity   string
+	AddressStreet string
+	AddressGeoLat float64
+	BillingCity   string
+}
+
+// :quickcopy --flatten
+func CopyOrderRow(dst *OrderRow, src *Order) {
+	dst.ID = src.ID
+	if src.Address != nil {
+		dst.AddressCity = src.Address.City
+	}
+	if src.Address != nil {
+		dst.AddressStreet = src.Address.Street
+	}
+	if src.Address != nil && src.Address.Geo != nil {
+		dst.AddressGeoLat = src.Address.Geo.Lat
+	}
+	dst.BillingCity = src.Billing.City
+}
+
+// :quickcopy --flatten
+func CopyOrder(dst *Order, src *OrderRow) {
+	dst.ID = src.ID
+	if dst.Address == nil {
+		dst.Address = new(Address)
+	}
+	dst.Address.City = src.AddressCity
+	if dst.Address == nil {
+		dst.Address = new(Address)
+	}
+	dst.Address.Street = src.AddressStreet
+	if dst.Address == nil {
+		dst.Address = new(Address)
+	}
+	if dst.Address.Geo == nil {
+		dst.Address.Geo = new(Geo)
+	}
+	dst.Address.Geo.Lat = src.AddressGeoLat
+	dst.Billing.City = src.BillingCity
+}
+
+// A.BC 和 AB.C 拼接后都是 ABC，有歧义时不拷贝
+type Inner struct {
+	BC string
+	C  string
+}
+
+type Ambiguous struct {
+	A  Inner
+	AB Inner
+}
+
+type AmbiguousRow struct {
+	ABC string
+}
+
+// :quickcopy --flatten
+func CopyAmbiguousRow(dst *AmbiguousRow, src *Ambiguous) {
+}
+
+func TestFlatten(t *testing.T) {
+	src := Order{
+		ID:      1,
+		Address: &Address{City: "Shanghai", Street: "Nanjing Rd", Geo: &Geo{Lat: 31.2}},
+		Billing: Address{City: "Beijing"},
+	}
+	var dst OrderRow
+	CopyOrderRow(&dst, &src)
+
+	want := OrderRow{ID: 1, AddressCity: "Shanghai", AddressStreet: "Nanjing Rd", AddressGeoLat: 31.2, BillingCity: "Beijing"}
+	if dst != want {
+		t.Errorf("got %+v, want %+v", dst, want)
+	}
+
+	// 源路径上的指针为 nil 时跳过
+	dst = OrderRow{}
+	CopyOrderRow(&dst, &Order{ID: 2})
+	if dst != (OrderRow{ID: 2}) {
+		t.Errorf("got %+v", dst)
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	src := OrderRow{ID: 1, AddressCity: "Shanghai", AddressStreet: "Nanjing Rd", AddressGeoLat: 31.2, BillingCity: "Beijing"}
+	var dst Order
+	CopyOrder(&dst, &src)
+
+	want := Order{
+		ID:      1,
+		Address: &Address{City: "Shanghai", Street: "Nanjing Rd", Geo: &Geo{Lat: 31.2}},
+		Billing: Address{City: "Beijing"},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got %+v, want %+v", dst, want)
+	}
+}
+
+func TestFlattenAmbiguous(t *testing.T) {
+	var dst AmbiguousRow
+	CopyAmbiguousRow(&dst, &Ambiguous{A: Inner{BC: "a"}, AB: Inner{C: "b"}})
+	if dst.ABC != "" {
+		t.Errorf("ambiguous field should not be copied, got %q", dst.ABC)
+	}
+}
@@ -0,0 +1,52 @@
+package genfile
+
+import (
+	"testing"
+	"time"
+)
+
+type itemRow struct {
+	ID    int64
+	Title *string
+}
+
+// 测试文件中的原型生成到 zz_quickcopy_gen_test.go，原型可以省略参数名
+var (
+	// :quickcopy
+	_ func(*Item, *itemRow) = copyItemFromRow
+)
+
+func TestGenFile(t *testing.T) {
+	src := Item{
+		ID:        42,
+		Title:     "book",
+		Tags:      []Tag{{Name: "new"}},
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	var view ItemView
+	CopyItemView(&view, &src)
+	if view.ID != "42" || view.Title != "book" || view.Tags[0].Name != "new" || view.CreatedAt != "2024-01-02T03:04:05Z" {
+		t.Fatalf("unexpected view: %+v", view)
+	}
+
+	var back Item
+	if err := CopyItem(&back, &view); err != nil {
+		t.Fatal(err)
+	}
+	if back.ID != src.ID || !back.CreatedAt.Equal(src.CreatedAt) || back.Tags[0] != src.Tags[0] {
+		t.Errorf("unexpected item: %+v", back)
+	}
+
+	view.ID = "x"
+	if err := CopyItem(&back, &view); err == nil {
+		t.Error("expected error for invalid ID")
+	}
+
+	title := "pen"
+	var fromRow Item
+	copyItemFromRow(&fromRow, &itemRow{ID: 1, Title: &title})
+	if fromRow.ID != 1 || fromRow.Title != "pen" {
+		t.Errorf("unexpected item: %+v", fromRow)
+	}
+}
@@ -0,0 +1,33 @@
+package genfile
+
+import "time"
+
+type Tag struct {
+	Name string
+}
+
+type Item struct {
+	ID        int64
+	Title     string
+	Tags      []Tag
+	CreatedAt time.Time
+}
+
+type TagView struct {
+	Name string
+}
+
+type ItemView struct {
+	ID        string
+	Title     string
+	Tags      []TagView
+	CreatedAt string
+}
+
+// 原型只是一个声明，函数体和辅助函数生成到 zz_quickcopy_gen.go
+//
+// :quickcopy
+var _ func(dst *ItemView, src *Item) = CopyItemView
+
+// :quickcopy
+var _ func(dst *Item, src *ItemView) error = CopyItem
@@ -0,0 +1,73 @@
+// Code generated by quickcopy. DO NOT EDIT.
+
+package genfile
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// CopyItemView 是一个自动生成的拷贝函数
+func CopyItemView(dst *ItemView, src *Item) {
+	dst.ID = func(i int64) string {
+		return strconv.FormatInt(i, 10)
+	}(src.ID)
+	dst.Title = src.Title
+	dst.Tags = copySliceTagViewFromSliceTag(src.Tags)
+	dst.CreatedAt = func(t time.Time) string {
+		return t.Format(time.RFC3339)
+	}(src.CreatedAt)
+}
+
+// CopyItem 是一个自动生成的拷贝函数
+func CopyItem(dst *Item, src *ItemView) error {
+	var err error
+	if dst.ID, err = func(s string) (int64, error) {
+		return strconv.ParseInt(s, 10, 64)
+	}(src.ID); err != nil {
+		return fmt.Errorf("ID: %w", err)
+	}
+	dst.Title = src.Title
+	dst.Tags = copySliceTagFromSliceTagView(src.Tags)
+	if dst.CreatedAt, err = func(s string) (time.Time, error) {
+		return time.Parse(time.RFC3339, s)
+	}(src.CreatedAt); err != nil {
+		return fmt.Errorf("CreatedAt: %w", err)
+	}
+	return nil
+}
+
+// copySliceTagFromSliceTagView 是自动生成的切片拷贝函数
+func copySliceTagFromSliceTagView(src []TagView) []Tag {
+	if src == nil {
+		return nil
+	}
+	dst := make([]Tag, len(src))
+	for i := range src {
+		copyTagFromTagView(&dst[i], &src[i])
+	}
+	return dst
+}
+
+// copySliceTagViewFromSliceTag 是自动生成的切片拷贝函数
+func copySliceTagViewFromSliceTag(src []Tag) []TagView {
+	if src == nil {
+		return nil
+	}
+	dst := make([]TagView, len(src))
+	for i := range src {
+		copyTagViewFromTag(&dst[i], &src[i])
+	}
+	return dst
+}
+
+// copyTagFromTagView 是一个自动生成的拷贝函数
+func copyTagFromTagView(dst *Tag, src *TagView) {
+	dst.Name = src.Name
+}
+
+// copyTagViewFromTag 是一个自动生成的拷贝函数
+func copyTagViewFromTag(dst *TagView, src *Tag) {
+	dst.Name = src.Name
+}
@@ -0,0 +1,103 @@
+package match_normalized
+
+import (
+	"reflect"
+	"testing"
+)
+
+// UserPB 模拟 protobuf 生成的结构体
+type UserPB struct {
+	UserId     int64
+	HttpUrl    string
+	Address_2  string
+	SrcName    string
+	NickName   string
+	ApiKeyHash string
+	UserIds    []int64
+	Ipv4_addr  string
+}
+
+type User struct {
+	UserID      int64
+	HTTPURL     string
+	Address2    string
+	Name        string
+	NickNameDTO string
+	APIKeyHash  string
+	UserIDs     []int64 // 缩写的复数
+	IPv4Addr    string
+}
+
+// :quickcopy --match=normalized --strip=Src,DTO
+func CopyUser(dst *User, src *UserPB) {
+	dst.UserID = src.UserId
+	dst.HTTPURL = src.HttpUrl
+	dst.Address2 = src.Address_2
+	dst.Name = src.SrcName
+	dst.NickNameDTO = src.NickName
+	dst.APIKeyHash = src.ApiKeyHash
+	dst.UserIDs = src.UserIds
+	dst.IPv4Addr = src.Ipv4_addr
+}
+
+// 不去掉前缀、后缀时 SrcName、NickNameDTO 不匹配
+// :quickcopy --match=normalized
+func CopyUserNoStrip(dst *User, src *UserPB) {
+	dst.UserID = src.UserId
+	dst.HTTPURL = src.HttpUrl
+	dst.Address2 = src.Address_2
+	dst.APIKeyHash = src.ApiKeyHash
+	dst.UserIDs = src.UserIds
+	dst.IPv4Addr = src.Ipv4_addr
+}
+
+func newUserPB() UserPB {
+	return UserPB{
+		UserId:     1,
+		HttpUrl:    "http://example.com",
+		Address_2:  "addr",
+		SrcName:    "alice",
+		NickName:   "ali",
+		ApiKeyHash: "hash",
+		UserIds:    []int64{2, 3},
+		Ipv4_addr:  "127.0.0.1",
+	}
+}
+
+func TestCopyUser(t *testing.T) {
+	src := newUserPB()
+	var dst User
+	CopyUser(&dst, &src)
+
+	want := User{
+		UserID:      1,
+		HTTPURL:     "http://example.com",
+		Address2:    "addr",
+		Name:        "alice",
+		NickNameDTO: "ali",
+		APIKeyHash:  "hash",
+		UserIDs:     []int64{2, 3},
+		IPv4Addr:    "127.0.0.1",
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got %+v, want %+v", dst, want)
+	}
+}
+
+func TestCopyUserNoStrip(t *testing.T) {
+	src := newUserPB()
+	var dst User
+	CopyUserNoStrip(&dst, &src)
+
+	want := User{
+		UserID:     1,
+		HTTPURL:    "http://example.com",
+		Address2:   "addr",
+		APIKeyHash: "hash",
+		UserIDs:    []int64{2, 3},
+		IPv4Addr:   "127.0.0.1",
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got %+v, want %+v", dst, want)
+	}
+}
@@ -0,0 +1,64 @@
+package match_tag
+
+import (
+	"reflect"
+	"testing"
+)
+
+type UserModel struct {
+	ID        int64  `db:"user_id"`
+	Name      string `db:"user_name" json:"name"`
+	Email     string `db:"email_address"`
+	Password  string `db:"-" json:"password"`
+	CreatedAt int64
+}
+
+type UserAPI struct {
+	UserID    int64  `json:"user_id,omitempty"`
+	Nickname  string `json:"name"`
+	Mail      string `db:"email_address" json:"mail"`
+	Secret    string `json:"password"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// :quickcopy --match-tag=json,db
+func CopyUserAPI(dst *UserAPI, src *UserModel) {
+	dst.Nickname = src.Name
+	dst.Mail = src.Email
+	dst.Secret = src.Password
+	dst.CreatedAt = src.CreatedAt
+}
+
+type UserRow struct {
+	UserID int64  `db:"user_id"`
+	Name   string `db:"user_name"`
+}
+
+// 只按 db 标签匹配时，json 标签相同也不会匹配
+// :quickcopy --match-tag=db
+func CopyUserRow(dst *UserRow, src *UserModel) {
+	dst.UserID = src.ID
+	dst.Name = src.Name
+}
+
+func TestCopyUserAPI(t *testing.T) {
+	src := UserModel{ID: 1, Name: "alice", Email: "a@example.com", Password: "pw", CreatedAt: 100}
+	var dst UserAPI
+	CopyUserAPI(&dst, &src)
+
+	want := UserAPI{Nickname: "alice", Mail: "a@example.com", Secret: "pw", CreatedAt: 100}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got %+v, want %+v", dst, want)
+	}
+}
+
+func TestCopyUserRow(t *testing.T) {
+	src := UserModel{ID: 1, Name: "alice"}
+	var dst UserRow
+	CopyUserRow(&dst, &src)
+
+	want := UserRow{UserID: 1, Name: "alice"}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got %+v, want %+v", dst, want)
+	}
+}
@@ -0,0 +1,111 @@
+package namedtype
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/antlabs/quickcopy/mytest/namedtype/remote"
+)
+
+type UserID int64
+
+type Status string
+
+type Score = float64
+
+type Address struct {
+	City string
+	Zip  string
+}
+
+type UserModel struct {
+	ID      UserID
+	Status  Status
+	Score   Score
+	Owner   UserID
+	Address remote.Address
+}
+
+type UserDTO struct {
+	ID      int64
+	Status  string
+	Score   float64
+	Owner   string
+	Address Address
+}
+
+// :quickcopy
+func CopyUserDTO(dst *UserDTO, src *UserModel) {
+	dst.ID = int64(src.ID)
+	dst.Status = string(src.Status)
+	dst.Score = src.Score
+	dst.Owner = func(v UserID) string {
+		return func(i int64) string {
+			return strconv.FormatInt(i, 10)
+		}(int64(v))
+	}(src.Owner)
+	copyAddressFromRemote_Address(&dst.Address, &src.Address)
+}
+
+// :quickcopy
+func CopyUserModel(dst *UserModel, src *UserDTO) {
+	dst.ID = UserID(src.ID)
+	dst.Status = Status(src.Status)
+	dst.Score = src.Score
+	dst.Owner = func(v string) UserID {
+		return UserID(func(s string) int64 {
+			i, _ := strconv.ParseInt(s, 10, 64)
+			return i
+		}(v))
+	}(src.Owner)
+	copyRemote_AddressFromAddress(&dst.Address, &src.Address)
+}
+
+func TestCopyUserDTO(t *testing.T) {
+	src := &UserModel{
+		ID:      42,
+		Status:  "active",
+		Score:   9.5,
+		Owner:   7,
+		Address: remote.Address{City: "Shanghai", Zip: 200000},
+	}
+	dst := &UserDTO{}
+
+	CopyUserDTO(dst, src)
+
+	if dst.ID != 42 {
+		t.Errorf("ID: got %d, want 42", dst.ID)
+	}
+	if dst.Status != "active" {
+		t.Errorf("Status: got %s, want active", dst.Status)
+	}
+	if dst.Score != 9.5 {
+		t.Errorf("Score: got %v, want 9.5", dst.Score)
+	}
+	if dst.Owner != "7" {
+		t.Errorf("Owner: got %s, want 7", dst.Owner)
+	}
+	if dst.Address.City != "Shanghai" || dst.Address.Zip != "200000" {
+		t.Errorf("Address: got %+v", dst.Address)
+	}
+}
+
+func TestCopyUserModel(t *testing.T) {
+	src := &UserDTO{
+		ID:      42,
+		Status:  "active",
+		Score:   9.5,
+		Owner:   "7",
+		Address: Address{City: "Shanghai", Zip: "200000"},
+	}
+	dst := &UserModel{}
+
+	CopyUserModel(dst, src)
+
+	if dst.ID != 42 || dst.Status != "active" || dst.Score != 9.5 || dst.Owner != 7 {
+		t.Errorf("got %+v", dst)
+	}
+	if dst.Address.City != "Shanghai" || dst.Address.Zip != 200000 {
+		t.Errorf("Address: got %+v", dst.Address)
+	}
+}
@@ -0,0 +1,7 @@
+package remote
+
+// Address 与 namedtype.Address 同名，但字段类型不同
+type Address struct {
+	City string
+	Zip  int
+}
@@ -0,0 +1,24 @@
+// Code generated by quickcopy. DO NOT EDIT.
+
+package namedtype
+
+import (
+	"fmt"
+	"github.com/antlabs/quickcopy/mytest/namedtype/remote"
+	"strconv"
+)
+
+// copyAddressFromRemote_Address 是一个自动生成的拷贝函数
+func copyAddressFromRemote_Address(dst *Address, src *remote.Address) {
+	dst.City = src.City
+	dst.Zip = fmt.Sprint(src.Zip)
+}
+
+// copyRemote_AddressFromAddress 是一个自动生成的拷贝函数
+func copyRemote_AddressFromAddress(dst *remote.Address, src *Address) {
+	dst.City = src.City
+	dst.Zip = func(s string) int {
+		i, _ := strconv.Atoi(s)
+		return i
+	}(src.Zip)
+}
@@ -0,0 +1,111 @@
+package nested_path
+
+import (
+	"reflect"
+	"testing"
+)
+
+type CityInfo struct {
+	Name string
+	Code string
+}
+
+type Location struct {
+	City    *CityInfo
+	Country string
+}
+
+type Meta struct {
+	Nick string
+}
+
+type User struct {
+	Meta
+	Name     string
+	Location *Location
+	Zip      int
+}
+
+type Address struct {
+	City    string
+	Country string
+	Zip     int64
+}
+
+type Profile struct {
+	Name string
+	Nick string
+}
+
+type UserView struct {
+	Profile Profile
+	Address *Address
+	Code    string
+}
+
+// :quickcopy Profile.Name = Name, Profile.Nick = Meta.Nick, Address.City = Location.City.Name, Address.Country = Location.Country, Address.Zip = Zip, Code = Location.City.Code
+func CopyUserView(dst *UserView, src *User) {
+	dst.Profile.Name = src.Name
+	dst.Profile.Nick = src.Meta.Nick
+	if src.Location != nil && src.Location.City != nil {
+		if dst.Address == nil {
+			dst.Address = new(Address)
+		}
+		dst.Address.City = src.Location.City.Name
+	}
+	if src.Location != nil {
+		if dst.Address == nil {
+			dst.Address = new(Address)
+		}
+		dst.Address.Country = src.Location.Country
+	}
+	if dst.Address == nil {
+		dst.Address = new(Address)
+	}
+	dst.Address.Zip = int64(src.Zip)
+	if src.Location != nil && src.Location.City != nil {
+		dst.Code = src.Location.City.Code
+	}
+}
+
+func TestCopyUserView(t *testing.T) {
+	src := User{
+		Meta:     Meta{Nick: "ali"},
+		Name:     "alice",
+		Location: &Location{City: &CityInfo{Name: "Shanghai", Code: "SH"}, Country: "CN"},
+		Zip:      200000,
+	}
+	var dst UserView
+	CopyUserView(&dst, &src)
+
+	want := UserView{
+		Profile: Profile{Name: "alice", Nick: "ali"},
+		Address: &Address{City: "Shanghai", Country: "CN", Zip: 200000},
+		Code:    "SH",
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got %+v, want %+v", dst, want)
+	}
+}
+
+// 源路径上的指针为 nil 时跳过，不会 panic
+func TestCopyUserViewNilSource(t *testing.T) {
+	src := User{Name: "alice", Zip: 1}
+	var dst UserView
+	CopyUserView(&dst, &src)
+
+	want := UserView{
+		Profile: Profile{Name: "alice"},
+		Address: &Address{Zip: 1},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got %+v, want %+v", dst, want)
+	}
+
+	src.Location = &Location{Country: "CN"}
+	dst = UserView{}
+	CopyUserView(&dst, &src)
+	if dst.Address.Country != "CN" || dst.Address.City != "" || dst.Code != "" {
+		t.Errorf("got %+v", dst.Address)
+	}
+}
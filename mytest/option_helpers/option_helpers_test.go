@@ -0,0 +1,77 @@
+package option_helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+type ItemReq struct {
+	ItemName string
+	Count    int64
+}
+
+type Item struct {
+	Itemname string
+	Count    int32
+}
+
+type OrderReq struct {
+	Items []ItemReq
+	Main  *ItemReq
+}
+
+type Order struct {
+	Items []Item
+	Main  *Item
+}
+
+// 相同的嵌套类型对，选项不同，生成不同的辅助函数
+// :quickcopy
+func CopyOrder(dst *Order, src *OrderReq) {
+	dst.Items = copySliceItemFromSliceItemReq(src.Items)
+	dst.Main = func(src *ItemReq) *Item {
+		if src == nil {
+			return nil
+		}
+		dst := new(Item)
+		copyItemFromItemReq(dst, src)
+		return dst
+	}(src.Main)
+}
+
+// :quickcopy --ignore-case --allow-narrow
+func CopyOrderLoose(dst *Order, src *OrderReq) {
+	dst.Items = copySliceItemFromSliceItemReqIgnoreCaseNarrow(src.Items)
+	dst.Main = func(src *ItemReq) *Item {
+		if src == nil {
+			return nil
+		}
+		dst := new(Item)
+		copyItemFromItemReqIgnoreCaseNarrow(dst, src)
+		return dst
+	}(src.Main)
+}
+
+func TestOptionHelpers(t *testing.T) {
+	src := OrderReq{
+		Items: []ItemReq{{ItemName: "apple", Count: 3}},
+		Main:  &ItemReq{ItemName: "pear", Count: 1},
+	}
+
+	var strict Order
+	CopyOrder(&strict, &src)
+	want := Order{Items: []Item{{}}, Main: &Item{}}
+	if !reflect.DeepEqual(strict, want) {
+		t.Errorf("CopyOrder() = %+v, want %+v", strict, want)
+	}
+
+	var loose Order
+	CopyOrderLoose(&loose, &src)
+	want = Order{
+		Items: []Item{{Itemname: "apple", Count: 3}},
+		Main:  &Item{Itemname: "pear", Count: 1},
+	}
+	if !reflect.DeepEqual(loose, want) {
+		t.Errorf("CopyOrderLoose() = %+v, want %+v", loose, want)
+	}
+}
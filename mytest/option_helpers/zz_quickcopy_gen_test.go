@@ -0,0 +1,37 @@
+// Code generated by quickcopy. DO NOT EDIT.
+
+package option_helpers
+
+// copyItemFromItemReq 是一个自动生成的拷贝函数
+func copyItemFromItemReq(dst *Item, src *ItemReq) {
+}
+
+// copyItemFromItemReqIgnoreCaseNarrow 是一个自动生成的拷贝函数
+func copyItemFromItemReqIgnoreCaseNarrow(dst *Item, src *ItemReq) {
+	dst.Itemname = src.ItemName
+	dst.Count = int32(src.Count)
+}
+
+// copySliceItemFromSliceItemReq 是自动生成的切片拷贝函数
+func copySliceItemFromSliceItemReq(src []ItemReq) []Item {
+	if src == nil {
+		return nil
+	}
+	dst := make([]Item, len(src))
+	for i := range src {
+		copyItemFromItemReq(&dst[i], &src[i])
+	}
+	return dst
+}
+
+// copySliceItemFromSliceItemReqIgnoreCaseNarrow 是自动生成的切片拷贝函数
+func copySliceItemFromSliceItemReqIgnoreCaseNarrow(src []ItemReq) []Item {
+	if src == nil {
+		return nil
+	}
+	dst := make([]Item, len(src))
+	for i := range src {
+		copyItemFromItemReqIgnoreCaseNarrow(&dst[i], &src[i])
+	}
+	return dst
+}
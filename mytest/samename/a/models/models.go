@@ -0,0 +1,6 @@
+package models
+
+// Address 和 b/models.Address 包名、类型名都相同
+type Address struct {
+	City string
+}
@@ -0,0 +1,6 @@
+package models
+
+type Address struct {
+	City string
+	Zip  string
+}
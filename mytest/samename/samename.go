@@ -0,0 +1,28 @@
+package samename
+
+import (
+	am "github.com/antlabs/quickcopy/mytest/samename/a/models"
+	bm "github.com/antlabs/quickcopy/mytest/samename/b/models"
+)
+
+type Address struct {
+	City string
+	Zip  string
+}
+
+type Order struct {
+	Ship  am.Address
+	Bill  bm.Address
+	Stops []am.Address
+}
+
+type OrderView struct {
+	Ship  Address
+	Bill  Address
+	Stops []Address
+}
+
+// 两个 models 包在生成文件中分别导入为 amodels 和 bmodels，辅助函数也不会重名
+//
+// :quickcopy
+var _ func(dst *OrderView, src *Order) = CopyOrderView
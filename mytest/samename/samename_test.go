@@ -0,0 +1,28 @@
+package samename
+
+import (
+	"testing"
+
+	am "github.com/antlabs/quickcopy/mytest/samename/a/models"
+	bm "github.com/antlabs/quickcopy/mytest/samename/b/models"
+)
+
+func TestCopyOrderView(t *testing.T) {
+	src := &Order{
+		Ship:  am.Address{City: "Shanghai"},
+		Bill:  bm.Address{City: "Beijing", Zip: "100000"},
+		Stops: []am.Address{{City: "Suzhou"}},
+	}
+	var dst OrderView
+	CopyOrderView(&dst, src)
+
+	if dst.Ship != (Address{City: "Shanghai"}) {
+		t.Errorf("Ship: got %+v", dst.Ship)
+	}
+	if dst.Bill != (Address{City: "Beijing", Zip: "100000"}) {
+		t.Errorf("Bill: got %+v", dst.Bill)
+	}
+	if len(dst.Stops) != 1 || dst.Stops[0].City != "Suzhou" {
+		t.Errorf("Stops: got %+v", dst.Stops)
+	}
+}
@@ -0,0 +1,38 @@
+// Code generated by quickcopy. DO NOT EDIT.
+
+package samename
+
+import (
+	amodels "github.com/antlabs/quickcopy/mytest/samename/a/models"
+	bmodels "github.com/antlabs/quickcopy/mytest/samename/b/models"
+)
+
+// CopyOrderView 是一个自动生成的拷贝函数
+func CopyOrderView(dst *OrderView, src *Order) {
+	copyAddressFromAmodels_Address(&dst.Ship, &src.Ship)
+	copyAddressFromBmodels_Address(&dst.Bill, &src.Bill)
+	dst.Stops = copySliceAddressFromSliceAmodels_Address(src.Stops)
+}
+
+// copyAddressFromAmodels_Address 是一个自动生成的拷贝函数
+func copyAddressFromAmodels_Address(dst *Address, src *amodels.Address) {
+	dst.City = src.City
+}
+
+// copyAddressFromBmodels_Address 是一个自动生成的拷贝函数
+func copyAddressFromBmodels_Address(dst *Address, src *bmodels.Address) {
+	dst.City = src.City
+	dst.Zip = src.Zip
+}
+
+// copySliceAddressFromSliceAmodels_Address 是自动生成的切片拷贝函数
+func copySliceAddressFromSliceAmodels_Address(src []amodels.Address) []Address {
+	if src == nil {
+		return nil
+	}
+	dst := make([]Address, len(src))
+	for i := range src {
+		copyAddressFromAmodels_Address(&dst[i], &src[i])
+	}
+	return dst
+}
@@ -0,0 +1,46 @@
+package strict
+
+import (
+	"reflect"
+	"testing"
+)
+
+type Base struct {
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+type User struct {
+	Base
+	Name     string
+	Email    string
+	Password string
+}
+
+type UserView struct {
+	Base
+	Name     string
+	Email    string
+	Password string
+	Avatar   string
+	Internal string `quickcopy:"-"`
+}
+
+// Avatar 没有来源，Password 有来源但不希望拷贝
+// :quickcopy --strict -Password, -Avatar, -UpdatedAt
+func CopyUserView(dst *UserView, src *User) {
+	dst.CreatedAt = src.CreatedAt
+	dst.Name = src.Name
+	dst.Email = src.Email
+}
+
+func TestCopyUserView(t *testing.T) {
+	src := User{Base: Base{CreatedAt: 1, UpdatedAt: 2}, Name: "alice", Email: "a@example.com", Password: "pw"}
+	var dst UserView
+	CopyUserView(&dst, &src)
+
+	want := UserView{Base: Base{CreatedAt: 1}, Name: "alice", Email: "a@example.com"}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got %+v, want %+v", dst, want)
+	}
+}
@@ -0,0 +1,130 @@
+package struct_tag
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func toCents(v float64) int64 {
+	return int64(v*100 + 0.5)
+}
+
+func parseSKU(s string) (int, error) {
+	return strconv.Atoi(s)
+}
+
+type Item struct {
+	Price float64
+	SKU   string
+}
+
+type ItemDTO struct {
+	PriceCents int64 `quickcopy:"name=Price,conv=toCents"`
+	SKU        int   `quickcopy:"conv=parseSKU"`
+}
+
+type Order struct {
+	ID      int64
+	UserID  int64
+	Amount  float64
+	Secret  string
+	Note    string `quickcopy:"-"`
+	Comment string
+	Remark  string `quickcopy:"name=Comment"`
+	Items   []Item
+	Main    Item
+}
+
+type OrderDTO struct {
+	ID      int64
+	Buyer   int64  `json:"buyer" quickcopy:"name=UserID"`
+	Cents   int64  `quickcopy:"name=Amount,conv=toCents"`
+	Secret  string `quickcopy:"-"`
+	Note    string
+	Comment string
+	Items   []ItemDTO
+	Main    ItemDTO
+}
+
+// :quickcopy
+func CopyOrderDTO(dst *OrderDTO, src *Order) {
+	dst.ID = src.ID
+	dst.Buyer = src.UserID
+	dst.Cents = toCents(src.Amount)
+	dst.Comment = src.Remark
+	dst.Items = copySliceItemDTOFromSliceItem(src.Items)
+	copyItemDTOFromItem(&dst.Main, &src.Main)
+}
+
+// 标签同样作用于返回 error 的拷贝函数生成的辅助函数
+// :quickcopy
+func CopyOrderDTOErr(dst *OrderDTO, src *Order) error {
+	var err error
+	dst.ID = src.ID
+	dst.Buyer = src.UserID
+	dst.Cents = toCents(src.Amount)
+	dst.Comment = src.Remark
+	if dst.Items, err = copySliceItemDTOFromSliceItemErr(src.Items); err != nil {
+		return fmt.Errorf("Items%w", err)
+	}
+	if err = copyItemDTOFromItemErr(&dst.Main, &src.Main); err != nil {
+		return fmt.Errorf("Main.%w", err)
+	}
+	return nil
+}
+
+func newOrder() Order {
+	return Order{
+		ID:      1,
+		UserID:  2,
+		Amount:  12.34,
+		Secret:  "secret",
+		Note:    "note",
+		Remark:  "remark",
+		Comment: "comment",
+		Items:   []Item{{Price: 1.5, SKU: "10"}, {Price: 2, SKU: "x"}},
+		Main:    Item{Price: 0.99, SKU: "7"},
+	}
+}
+
+func TestCopyOrderDTO(t *testing.T) {
+	var dst OrderDTO
+	src := newOrder()
+	CopyOrderDTO(&dst, &src)
+
+	want := OrderDTO{
+		ID:      1,
+		Buyer:   2,
+		Cents:   1234,
+		Comment: "remark",
+		Items:   []ItemDTO{{PriceCents: 150, SKU: 10}, {PriceCents: 200}},
+		Main:    ItemDTO{PriceCents: 99, SKU: 7},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got %+v, want %+v", dst, want)
+	}
+}
+
+func TestCopyOrderDTOErr(t *testing.T) {
+	var dst OrderDTO
+	src := newOrder()
+	err := CopyOrderDTOErr(&dst, &src)
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Fatalf("want strconv error, got %v", err)
+	}
+	if got, want := err.Error(), `Items[1].SKU: strconv.Atoi: parsing "x": invalid syntax`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	src.Items[1].SKU = "11"
+	if err := CopyOrderDTOErr(&dst, &src); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Items[1].SKU != 11 || dst.Main.SKU != 7 {
+		t.Errorf("got %+v", dst)
+	}
+}
@@ -0,0 +1,51 @@
+// Code generated by quickcopy. DO NOT EDIT.
+
+package struct_tag
+
+import "fmt"
+
+// copyItemDTOFromItem 是一个自动生成的拷贝函数
+func copyItemDTOFromItem(dst *ItemDTO, src *Item) {
+	dst.PriceCents = toCents(src.Price)
+	dst.SKU = func(v string) int {
+		r, _ := parseSKU(v)
+		return r
+	}(src.SKU)
+}
+
+// copyItemDTOFromItemErr 是一个自动生成的拷贝函数
+func copyItemDTOFromItemErr(dst *ItemDTO, src *Item) error {
+	var err error
+	dst.PriceCents = toCents(src.Price)
+	if dst.SKU, err = parseSKU(src.SKU); err != nil {
+		return fmt.Errorf("SKU: %w", err)
+	}
+	return nil
+}
+
+// copySliceItemDTOFromSliceItem 是自动生成的切片拷贝函数
+func copySliceItemDTOFromSliceItem(src []Item) []ItemDTO {
+	if src == nil {
+		return nil
+	}
+	dst := make([]ItemDTO, len(src))
+	for i := range src {
+		copyItemDTOFromItem(&dst[i], &src[i])
+	}
+	return dst
+}
+
+// copySliceItemDTOFromSliceItemErr 是自动生成的切片拷贝函数
+func copySliceItemDTOFromSliceItemErr(src []Item) ([]ItemDTO, error) {
+	if src == nil {
+		return nil, nil
+	}
+	dst := make([]ItemDTO, len(src))
+	var err error
+	for i := range src {
+		if err = copyItemDTOFromItemErr(&dst[i], &src[i]); err != nil {
+			return nil, fmt.Errorf("[%d].%w", i, err)
+		}
+	}
+	return dst, nil
+}
@@ -0,0 +1,18 @@
+package baddirective
+
+type User struct {
+	Name string
+	Age  int
+}
+
+type UserView struct {
+	Name string
+	Age  int
+}
+
+// :quickcopy --strictt --match=fuzzy
+// Name = Name, Age
+// Name = Age, -
+// Nick = "anonymous
+func CopyUserView(dst *UserView, src *User) {
+}
@@ -0,0 +1,17 @@
+package badexpr
+
+type User struct {
+	FirstName string
+	LastName  string
+	FullName  string // 规则写错时不能退回按名字拷贝这个字段
+}
+
+type UserView struct {
+	FullName string
+	Age      int
+	Level    int8
+}
+
+// :quickcopy FullName = src.FirstName + " " + src.LastNmae, Age = src.FirstName, Level = 300, _ = LastName, _ = FullName
+func CopyUserView(dst *UserView, src *User) {
+}
@@ -0,0 +1,23 @@
+package badproto
+
+type User struct {
+	Name string
+	Age  int
+}
+
+type UserDTO struct {
+	Name string
+	Age  int8
+}
+
+// :quickcopy
+func CopyMissingSrc(dst *UserDTO) {
+}
+
+// :quickcopy
+func CopyUnnamed(_ *UserDTO, _ *User) {
+}
+
+// :quickcopy
+func CopyUserDTO(dst *UserDTO, src *User) {
+}
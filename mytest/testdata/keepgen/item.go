@@ -0,0 +1,4 @@
+package keepgen
+
+// :quickcopy
+var _ func(dst *ItemView, src *Item) = CopyItemView
@@ -0,0 +1,31 @@
+package keepgen
+
+type Item struct {
+	Name string
+}
+
+type ItemView struct {
+	Name string
+}
+
+type Profile struct {
+	Bio string
+}
+
+type ProfileView struct {
+	Bio string
+}
+
+type User struct {
+	Name    string
+	Profile Profile
+	Scores  []int
+}
+
+// UserView 比生成 CopyUserView 时多了 Avatar，--strict 的原型生成失败
+type UserView struct {
+	Name    string
+	Profile ProfileView
+	Scores  []string
+	Avatar  string
+}
@@ -0,0 +1,11 @@
+package keepgen
+
+// :quickcopy --strict
+var _ func(dst *UserView, src *User) = CopyUserView
+
+// 包里其他代码使用生成的函数，生成失败时不能删除
+func NewUserView(u *User) *UserView {
+	var v UserView
+	CopyUserView(&v, u)
+	return &v
+}
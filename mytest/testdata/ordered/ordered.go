@@ -0,0 +1,29 @@
+package ordered
+
+import "strings"
+
+type Person struct {
+	Nick  string
+	Years int64
+	Town  string
+	Code  string
+	Addr  *Addr
+}
+
+type Addr struct {
+	Street string
+}
+
+type PersonView struct {
+	Name   string
+	Age    int
+	City   string
+	Zip    string
+	Street string
+	Note   string
+}
+
+// :quickcopy Zip = Code, City = Town, Name = strings.ToUpper(src.Nick), Age = int(src.Years)
+// Street = Addr.Street, -Remark, -Note, _ = Phone, _ = Fax
+func CopyPersonView(dst *PersonView, src *Person) {
+}
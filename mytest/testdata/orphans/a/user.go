@@ -0,0 +1,22 @@
+package a
+
+type AddressReq struct {
+	City string
+}
+
+type Address struct {
+	City string
+}
+
+type UserReq struct {
+	Address AddressReq
+}
+
+type User struct {
+	Address Address
+}
+
+// :quickcopy
+func CopyUser(dst *User, src *UserReq) {
+	copyAddressFromAddressReq(&dst.Address, &src.Address)
+}
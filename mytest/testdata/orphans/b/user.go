@@ -0,0 +1,6 @@
+package b
+
+// 拷贝函数已经删除，生成文件不再需要
+type User struct {
+	Name string
+}
@@ -0,0 +1,29 @@
+package money
+
+import "strconv"
+
+type Cents int64
+
+// :quickcopy-converter
+func FormatCents(c Cents) string {
+	return strconv.FormatInt(int64(c), 10)
+}
+
+// 未导出的转换函数只在 money 包内使用
+//
+// :quickcopy-converter
+func toCents(s string) Cents {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return Cents(n)
+}
+
+type Price struct {
+	Amount Cents
+}
+
+type PriceView struct {
+	Amount string
+}
+
+// :quickcopy
+var _ func(dst *Price, src *PriceView) = CopyPrice
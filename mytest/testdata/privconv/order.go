@@ -0,0 +1,19 @@
+package privconv
+
+import "github.com/antlabs/quickcopy/mytest/testdata/privconv/money"
+
+type Order struct {
+	Total money.Cents
+}
+
+type OrderView struct {
+	Total string
+}
+
+// :quickcopy
+func CopyOrderView(dst *OrderView, src *Order) {
+}
+
+// :quickcopy
+func CopyOrder(dst *Order, src *OrderView) {
+}
@@ -0,0 +1,21 @@
+package a
+
+type ItemReq struct {
+	Name string
+}
+
+type Item struct {
+	Name string
+}
+
+type OrderReq struct {
+	Items []ItemReq
+}
+
+type Order struct {
+	Items []Item
+}
+
+// :quickcopy
+func CopyOrder(dst *Order, src *OrderReq) {
+}
@@ -0,0 +1,19 @@
+package a
+
+import "strconv"
+
+type Refund struct {
+	Items []Item
+}
+
+// 和 CopyOrder 使用相同的辅助函数，同一个包中只生成一次
+// :quickcopy
+func CopyRefund(dst *Refund, src *OrderReq) {
+}
+
+// 以前的版本追加到源文件中的辅助函数，生成时删除，只有它使用的导入一并删除
+
+// copyItemFromItemReq 是一个自动生成的拷贝函数
+func copyItemFromItemReq(dst *Item, src *ItemReq) {
+	dst.Name = src.Name + strconv.Itoa(0)
+}
@@ -0,0 +1,22 @@
+package b
+
+type ItemReq struct {
+	Name string
+}
+
+type Item struct {
+	Name string
+}
+
+type OrderReq struct {
+	Items []ItemReq
+}
+
+type Order struct {
+	Items []Item
+}
+
+// 类型和包 a 中的同名，辅助函数在每个包中各自生成
+// :quickcopy
+func CopyOrder(dst *Order, src *OrderReq) {
+}
@@ -0,0 +1,21 @@
+package strict
+
+type User struct {
+	Name string
+}
+
+type Audit struct {
+	CreatedBy string
+}
+
+type UserView struct {
+	Audit
+	Name     string
+	Avatar   string
+	Internal string `quickcopy:"-"`
+	Nickname string
+}
+
+// :quickcopy --strict -Nickname
+func CopyUserView(dst *UserView, src *User) {
+}
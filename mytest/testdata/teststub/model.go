@@ -0,0 +1,22 @@
+package teststub
+
+type AddrReq struct {
+	City string
+}
+
+type Addr struct {
+	City string
+}
+
+type UserReq struct {
+	Name string
+	Addr AddrReq
+}
+
+type User struct {
+	Name string
+	Addr Addr
+}
+
+// :quickcopy
+var _ func(dst *User, src *UserReq) = CopyUser
@@ -0,0 +1,13 @@
+package teststub
+
+type ProfileReq struct {
+	Addr AddrReq
+}
+
+type Profile struct {
+	Addr Addr
+}
+
+// :quickcopy
+func copyProfile(dst *Profile, src *ProfileReq) {
+}
@@ -0,0 +1,16 @@
+package unused
+
+type User struct {
+	Name     string
+	Email    string
+	Password string
+	Token    string `quickcopy:"-"`
+}
+
+type UserView struct {
+	Name string
+}
+
+// :quickcopy --strict-source _ = Password
+func CopyUserView(dst *UserView, src *User) {
+}
@@ -1,11 +1,17 @@
 package quickcopy
 
-import "strings"
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strings"
+)
 
 // 工具函数
 func sanitizeTypeName(typeName string) string {
 	// 保留包名前缀但替换非法字符
 	typeName = strings.ReplaceAll(typeName, ".", "_")
+	typeName = strings.ReplaceAll(typeName, "map[", "Map_")
 	typeName = strings.ReplaceAll(typeName, "[]", "Slice_")
 	typeName = strings.ReplaceAll(typeName, "*", "Ptr_")
 	typeName = strings.ReplaceAll(typeName, "[", "Arr_")
@@ -18,13 +24,34 @@ func sanitizeTypeName(typeName string) string {
 	return typeName
 }
 
-// extractFieldName 从字段路径中提取字段名
-func extractFieldName(fieldPath string) string {
-	// 按点号分割路径
-	parts := strings.Split(fieldPath, ".")
-	if len(parts) == 0 {
-		return ""
-	}
-	// 返回最后一个部分（字段名）
-	return parts[len(parts)-1]
+// clearPositions 清除生成代码的位置信息。
+// 生成的节点来自另一个 FileSet，残留的位置会让 printer 把原文件的注释插到错误的地方
+func clearPositions(node ast.Node) {
+	posType := reflect.TypeOf(token.NoPos)
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		v := reflect.ValueOf(n)
+		if v.Kind() != reflect.Pointer || v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+		if v.Kind() != reflect.Struct {
+			return true
+		}
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Field(i)
+			if f.Type() != posType || !f.CanSet() {
+				continue
+			}
+			// CallExpr.Ellipsis 用是否有效来表示 f(args...)，不能清零
+			if v.Type().Field(i).Name == "Ellipsis" && f.Int() != int64(token.NoPos) {
+				f.SetInt(1)
+				continue
+			}
+			f.SetInt(int64(token.NoPos))
+		}
+		return true
+	})
 }
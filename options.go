@@ -0,0 +1,62 @@
+package quickcopy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// copyOptions 是影响字段对应关系和类型转换的选项。顶层拷贝函数的选项沿用到它生成的所有辅助函数，
+// 并且是辅助函数身份的一部分：选项不同的拷贝函数不会共用同一个 copyXFromY
+type copyOptions struct {
+	match         fieldMatcher // --ignore-case、--match、--strip、--match-tag、--flatten
+	allowNarrow   bool         // --allow-narrow
+	singleToSlice bool         // --single-to-slice
+}
+
+// suffix 返回辅助函数名中的选项后缀，默认选项为空，如 copyUserFromUserReqIgnoreCaseNarrow
+func (o copyOptions) suffix() string {
+	var b strings.Builder
+	m := o.match
+	if m.ignoreCase {
+		b.WriteString("IgnoreCase")
+	}
+	if m.normalized && len(m.strip) == 0 {
+		b.WriteString("Normalized")
+	}
+	if len(m.strip) > 0 {
+		b.WriteString("Strip")
+		for _, affix := range m.strip {
+			for _, w := range affix {
+				b.WriteString(upperFirst(w))
+			}
+		}
+	}
+	if len(m.tags) > 0 {
+		b.WriteString("Tag")
+		for _, tag := range m.tags {
+			b.WriteString(upperFirst(strings.Map(identRune, tag)))
+		}
+	}
+	if m.flatten {
+		b.WriteString("Flatten")
+	}
+	if o.allowNarrow {
+		b.WriteString("Narrow")
+	}
+	return b.String()
+}
+
+// identRune 去掉不能出现在函数名中的字符，如 json-api 中的 -
+func identRune(r rune) rune {
+	if unicode.IsLetter(r) || unicode.IsDigit(r) {
+		return r
+	}
+	return -1
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
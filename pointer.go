@@ -0,0 +1,137 @@
+package quickcopy
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// 新增指针转换处理函数
+func handlePointerConversion(srcType, dstType types.Type, opts copyOptions, mode errMode, env *typeEnv) (conversion, bool) {
+	// 获取基础类型
+	baseSrc := srcType.Underlying().(*types.Pointer).Elem()
+	baseDst := dstType.Underlying().(*types.Pointer).Elem()
+
+	// 递归获取基础类型转换
+	baseConv, ok := getTypeConversion(baseSrc, baseDst, opts, mode, env)
+	if !ok {
+		return conversion{}, false
+	}
+
+	stmt := generateElementConversion("*src", "*dst", baseConv)
+	if baseConv.IsStruct {
+		stmt = fmt.Sprintf("%s(dst, src)", baseConv.Func)
+	}
+
+	// 转换可能失败时把错误原样返回，由调用者加上字段路径
+	if baseConv.Fallible {
+		stmt = generateFallibleConversion("*src", "*dst", baseConv)
+		if baseConv.IsStruct {
+			stmt = fmt.Sprintf("err = %s(dst, src)", baseConv.Func)
+		}
+		return fallibleConversion(baseConv, `func(src %s) (dst %s, err error) {
+        if src == nil {
+            return nil, nil
+        }
+        dst = new(%s)
+        %s
+        return dst, err
+    }`, env.typeString(srcType), env.typeString(dstType), env.typeString(baseDst), stmt), true
+	}
+
+	// 生成指针转换逻辑
+	return conversion{Func: fmt.Sprintf(`func(src %s) %s {
+        if src == nil {
+            return nil
+        }
+        dst := new(%s)
+        %s
+        return dst
+    }`, env.typeString(srcType), env.typeString(dstType), env.typeString(baseDst), stmt), Opts: baseConv.Opts}, true
+}
+
+// handleDerefConversion 处理 *T -> U，源指针为 nil 时返回 defaultValue，
+// defaultValue 为空时返回零值。多级指针（**T）会逐层递归解引用
+func handleDerefConversion(srcType, dstType types.Type, defaultValue string, opts copyOptions, mode errMode, env *typeEnv) (conversion, bool) {
+	baseSrc := srcType.Underlying().(*types.Pointer).Elem()
+
+	baseConv, ok := getTypeConversion(baseSrc, dstType, opts, mode, env)
+	if !ok {
+		return conversion{}, false
+	}
+
+	stmt := generateElementConversion("*src", "dst", baseConv)
+	if baseConv.IsStruct {
+		stmt = fmt.Sprintf("%s(&dst, src)", baseConv.Func)
+	}
+
+	if baseConv.Fallible {
+		stmt = generateFallibleConversion("*src", "dst", baseConv)
+		if baseConv.IsStruct {
+			stmt = fmt.Sprintf("err = %s(&dst, src)", baseConv.Func)
+		}
+		if defaultValue != "" {
+			defaultValue += ", nil"
+		}
+		return fallibleConversion(baseConv, `func(src %s) (dst %s, err error) {
+        if src == nil {
+            return %s
+        }
+        %s
+        return dst, err
+    }`, env.typeString(srcType), env.typeString(dstType), defaultValue, stmt), true
+	}
+
+	return conversion{Func: fmt.Sprintf(`func(src %s) (dst %s) {
+        if src == nil {
+            return %s
+        }
+        %s
+        return dst
+    }`, env.typeString(srcType), env.typeString(dstType), defaultValue, stmt), Opts: baseConv.Opts}, true
+}
+
+// handleAddrConversion 处理 T -> *U，总是分配新的目标对象
+func handleAddrConversion(srcType, dstType types.Type, opts copyOptions, mode errMode, env *typeEnv) (conversion, bool) {
+	baseDst := dstType.Underlying().(*types.Pointer).Elem()
+
+	baseConv, ok := getTypeConversion(srcType, baseDst, opts, mode, env)
+	if !ok {
+		return conversion{}, false
+	}
+
+	stmt := generateElementConversion("src", "*dst", baseConv)
+	if baseConv.IsStruct {
+		stmt = fmt.Sprintf("%s(dst, &src)", baseConv.Func)
+	}
+
+	if baseConv.Fallible {
+		stmt = generateFallibleConversion("src", "*dst", baseConv)
+		if baseConv.IsStruct {
+			stmt = fmt.Sprintf("err = %s(dst, &src)", baseConv.Func)
+		}
+		return fallibleConversion(baseConv, `func(src %s) (dst %s, err error) {
+        dst = new(%s)
+        %s
+        return dst, err
+    }`, env.typeString(srcType), env.typeString(dstType), env.typeString(baseDst), stmt), true
+	}
+
+	return conversion{Func: fmt.Sprintf(`func(src %s) %s {
+        dst := new(%s)
+        %s
+        return dst
+    }`, env.typeString(srcType), env.typeString(dstType), env.typeString(baseDst), stmt), Opts: baseConv.Opts}, true
+}
+
+// fallibleConversion 返回包装了 inner 的函数字面量，错误原样透传，所以沿用 inner 的路径分隔符
+func fallibleConversion(inner conversion, format string, args ...any) conversion {
+	return conversion{Func: fmt.Sprintf(format, args...), Fallible: true, ErrSep: inner.ErrSep, Opts: inner.Opts}
+}
+
+// getFieldConversion 获取字段的类型转换，源字段是指针、目标字段不是指针时使用字段的默认值
+func getFieldConversion(srcType, dstType types.Type, defaultValue string, opts copyOptions, mode errMode, env *typeEnv) (conversion, bool) {
+	if defaultValue != "" && isPointerType(srcType) && !isPointerType(dstType) {
+		return handleDerefConversion(srcType, dstType, defaultValue, opts, mode, env)
+	}
+	return getTypeConversion(srcType, dstType, opts, mode, env)
+}
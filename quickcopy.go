@@ -2,35 +2,36 @@ package quickcopy
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/parser"
-	"go/printer"
 	"go/token"
 	"go/types"
-	"log"
-	"os"
-	"path/filepath"
 	"strings"
 	"text/template"
 )
 
-// 新增函数：注册生成的函数到AST
-var generatedFunctions Map[string, *ast.FuncDecl]
-
-var processedTopLevelTypes Map[string, bool]
-
 // FieldMapping 增加新字段
 type FieldMapping struct {
-	SrcField       string
-	DstField       string
-	Conversion     string
-	IsSlice        bool
-	IsEmbedded     bool
-	ConversionFunc string
-	SrcElemType    string // 新增
-	DstElemType    string // 新增
-	ImportPath     string // 依赖的包
+	SrcField   string
+	DstField   string
+	Conversion string
+	IsSlice    bool // 整体转换切片、数组、map 等非结构体类型
+	IsStruct   bool // Conversion 是结构体拷贝函数，按指针传参
+	Fallible   bool // Conversion 会返回 error
+	OnErr      string
+
+	SrcNilChecks []string    // 源字段路径上的中间指针，任何一个为 nil 时不拷贝
+	DstAllocs    []PathAlloc // 目标字段路径上的中间指针，为 nil 时先分配
+
+	Expr string // 表达式或者常量规则的右边，直接赋值给 DstField，SrcField 为空
+}
+
+// PathAlloc 是目标字段路径上需要分配的中间指针
+type PathAlloc struct {
+	Path string // 如 Address
+	Type string // 指针指向的类型
 }
 
 // CopyFuncInfo 存储拷贝函数信息
@@ -41,327 +42,272 @@ type CopyFuncInfo struct {
 	SrcType  string
 	DstType  string
 	Fields   []FieldMapping
+
+	ErrDecl   string // 声明 err、errs，没有可能失败的字段时为空
+	ReturnErr string // 拷贝函数返回 error 时结尾的返回值
 }
 
 // 修改后的完整 processFields 函数
 func processFields(
-	structType *ast.StructType,
-	srcStruct *ast.StructType,
-	file *ast.File,
+	structType *types.Struct,
+	srcStruct *types.Struct,
 	prefix string,
-	isSrc bool,
-	ignoreCase bool,
-	allowNarrow bool,
-	singleToSlice bool,
+	allocs []PathAlloc, // 内嵌结构体指针提升的字段，写入前要分配的指针
+	opts copyOptions,
+	mode errMode,
+	nilDefaults map[string]string,
 	fields *[]FieldMapping,
 	mappedDstFields map[string]bool,
-	path string,
+	env *typeEnv,
 ) {
-	for _, field := range structType.Fields.List {
-		// 处理嵌入字段（匿名和具名）
-		if len(field.Names) == 0 {
-			// 匿名嵌入字段
-			embeddedType := types.ExprString(field.Type)
-			if embeddedStruct := findStructDef(embeddedType, file, path); embeddedStruct != nil {
+	for i := 0; i < structType.NumFields(); i++ {
+		field := structType.Field(i)
+
+		// 处理内嵌字段
+		if field.Anonymous() {
+			if embedded, elem := embeddedStruct(field); embedded != nil {
+				embeddedAllocs := allocs
+				if elem != nil {
+					embeddedAllocs = append(append([]PathAlloc(nil), allocs...), PathAlloc{Path: prefix + field.Name(), Type: env.typeString(elem)})
+				}
 				processFields(
-					embeddedStruct,
+					embedded,
 					srcStruct,
-					file,
 					prefix, // 保持当前前缀实现字段提升
-					isSrc,
-					ignoreCase,
-					allowNarrow,
-					singleToSlice,
+					embeddedAllocs,
+					opts,
+					mode,
+					nilDefaults,
 					fields,
 					mappedDstFields,
-					path,
+					env,
 				)
 			}
 			continue
 		}
 
-		// 处理具名字段（包括具名嵌入）
-		for _, fieldName := range field.Names {
-			currentFieldPath := prefix + fieldName.Name
-			fieldType := types.ExprString(field.Type)
-
-			// 检查是否是具名嵌入结构体
-			if isStructType(fieldType, file, path) {
-				if embeddedStruct := findStructDef(fieldType, file, path); embeddedStruct != nil {
-					processFields(
-						embeddedStruct,
-						srcStruct,
-						file,
-						currentFieldPath+".", // 添加点号保持嵌套路径
-						isSrc,
-						ignoreCase,
-						allowNarrow,
-						singleToSlice,
-						fields,
-						mappedDstFields,
-						path,
-					)
-				}
-			}
+		if field.Name() == "_" || !env.isAccessible(field) || mappedDstFields[field.Name()] {
+			continue
+		}
+		tag := fieldTagAt(structType, i, env)
+		if tag.skip {
+			continue
+		}
+		currentFieldPath := prefix + field.Name()
 
-			// 普通字段处理逻辑
-			var srcField *ast.Field
-			var srcFieldName string
-			if isSrc {
-				srcField = field
-				srcFieldName = currentFieldPath
-			} else {
-				if ignoreCase {
-					srcField, srcFieldName = findFieldByNameIgnoreCase(srcStruct, fieldName.Name, file, path)
-				} else {
-					srcField = findFieldByName(srcStruct, fieldName.Name, file, path)
-					if srcField != nil {
-						srcFieldName = srcField.Names[0].Name
-					}
+		// 查找源字段：quickcopy 标签指定的名字优先，其次是 --match-tag 指定的标签，最后是字段名
+		var srcField *types.Var
+		var srcTag fieldTag
+		if tag.name == "" {
+			srcField, srcTag = findSourceFieldByTags(srcStruct, structType.Tag(i), opts.match.tags, env)
+		}
+		if srcField == nil {
+			srcField, srcTag = findSourceField(srcStruct, tag.key(field), opts.match, env)
+		}
+		var srcPath string
+		var srcNilChecks []string
+		if srcField == nil && opts.match.flatten {
+			// --flatten：AddressCity 来自 src.Address.City，或者 src.AddressCity 拷贝到 dst.Address.City
+			src, ok := findFlattenedSource(srcStruct, currentFieldPath, tag.key(field), opts.match, env)
+			if !ok {
+				if unflattenField(field, tag, srcStruct, allocs, opts, mode, nilDefaults, fields, env) {
+					mappedDstFields[field.Name()] = true
 				}
-			}
-
-			if srcField == nil {
 				continue
 			}
-
-			// 处理类型转换
-			srcType := types.ExprString(srcField.Type)
-			dstType := types.ExprString(field.Type)
-			conversion, importPath := getTypeConversion(srcType, dstType, allowNarrow, singleToSlice, file, path)
-
-			// 判断是否为嵌入字段
-			isEmbedded := false
-			if isStructType(srcType, file, path) && isStructType(dstType, file, path) {
-				if srcType == dstType {
-					isEmbedded = true
-					conversion = getStructCopyFuncName(srcType, dstType)
-				}
-			}
-
-			// 存储映射关系
-			*fields = append(*fields, FieldMapping{
-				SrcField:       srcFieldName,
-				DstField:       currentFieldPath,
-				Conversion:     conversion,
-				IsEmbedded:     isEmbedded,
-				ConversionFunc: getStructCopyFuncName(srcType, dstType),
-				SrcElemType:    getElementType(srcType),
-				DstElemType:    getElementType(dstType),
-				ImportPath:     importPath,
-			})
-
-			if !isSrc {
-				mappedDstFields[fieldName.Name] = true
-			}
+			srcField, srcTag, srcPath, srcNilChecks = src.field, src.tag, src.path, src.ptrs
 		}
-	}
-}
-
-// 新增 processNestedTypes 函数
-func processNestedTypes(structType *ast.StructType, file *ast.File, path string) {
-	for _, field := range structType.Fields.List {
-		fieldType := types.ExprString(field.Type)
-
-		// 处理指针类型
-		if strings.HasPrefix(fieldType, "*") {
-			elemType := strings.TrimPrefix(fieldType, "*")
-			if isStructType(elemType, file, path) {
-				generateCopyFunctionIfNeeded(elemType, elemType, file, path)
-			}
+		if srcField == nil {
 			continue
 		}
+		if srcPath == "" {
+			srcPath = srcField.Name()
+			srcNilChecks, _ = embeddedPtrs(srcStruct, srcField)
+		}
 
-		// 处理切片/数组类型
-		if isSliceOrArray(fieldType) {
-			elemType := getElementType(fieldType)
-			generateCopyFunctionIfNeeded(elemType, elemType, file, path)
+		// 处理类型转换
+		conv, ok := fieldConversion(srcField, field, srcTag, tag, nilDefaults[field.Name()], opts, mode, env)
+		if !ok {
+			env.warnf(token.NoPos, "skipping field %s: cannot convert %s to %s",
+				currentFieldPath, env.typeString(srcField.Type()), env.typeString(field.Type()))
 			continue
 		}
 
-		// 处理嵌套结构体
-		if isStructType(fieldType, file, path) {
-			generateCopyFunctionIfNeeded(fieldType, fieldType, file, path)
-		}
+		// 存储映射关系
+		m := newFieldMapping(srcPath, currentFieldPath, conv, mode, env)
+		m.SrcNilChecks = srcNilChecks
+		m.DstAllocs = allocs
+		*fields = append(*fields, m)
+
+		mappedDstFields[field.Name()] = true
 	}
 }
 
-// 新增 isExternalType 函数
-func isExternalType(typeName string, file *ast.File) bool {
-	// 处理指针类型
-	if strings.HasPrefix(typeName, "*") {
-		return isExternalType(strings.TrimPrefix(typeName, "*"), file)
-	}
-
-	// 处理包前缀
-	if strings.Contains(typeName, ".") {
-		pkgPart := strings.Split(typeName, ".")[0]
-		for _, imp := range file.Imports {
-			importedPath := strings.Trim(imp.Path.Value, `"`)
-			// 匹配导入路径最后部分或别名
-			if imp.Name != nil && imp.Name.Name == pkgPart {
-				return true
-			}
-			if filepath.Base(importedPath) == pkgPart {
-				return true
-			}
-		}
+// newFieldMapping 创建字段映射，转换可能失败时生成带目标字段路径的错误处理语句
+func newFieldMapping(srcField, dstField string, conv conversion, mode errMode, env *typeEnv) FieldMapping {
+	m := FieldMapping{
+		SrcField:   srcField,
+		DstField:   dstField,
+		Conversion: conv.Func,
+		IsStruct:   conv.IsStruct,
+		Fallible:   conv.Fallible,
 	}
-	return false
+	if conv.Fallible {
+		m.OnErr = mode.onErr("", dstField, conv, env)
+	}
+	return m
 }
 
-var generatedStructPairs Map[string, bool]
+// pairKey 返回类型对的唯一标识，使用完整包路径避免同名类型冲突
+func pairKey(srcType, dstType types.Type) string {
+	return types.TypeString(srcType, nil) + "->" + types.TypeString(dstType, nil)
+}
 
-func generateCopyFunctionIfNeeded(srcType, dstType string, file *ast.File, path string) {
-	if isSliceOrArray(srcType) && isSliceOrArray(dstType) {
-		srcElem := getElementType(srcType)
-		dstElem := getElementType(dstType)
-		// 递归处理元素类型
-		generateCopyFunctionIfNeeded(srcElem, dstElem, file, path)
-		return
-	}
+// helperKey 返回结构体拷贝函数的身份：类型对、错误处理方式以及选项
+func helperKey(srcType, dstType types.Type, opts copyOptions, mode errMode) string {
+	return pairKey(srcType, dstType) + mode.suffix() + "/" + opts.suffix()
+}
 
-	key := srcType + "->" + dstType
-	if _, ok := processedTopLevelTypes.Load(key); ok {
-		return
+// generateCopyFunctionIfNeeded 生成结构体拷贝函数并返回调用方式。
+// 拷贝函数返回 error 时，只有存在可能失败的字段才生成带后缀、返回 error 的版本；
+// 非默认选项生成的版本带有选项后缀，如 copyUserFromUserReqIgnoreCase
+func generateCopyFunctionIfNeeded(srcType, dstType types.Type, opts copyOptions, mode errMode, env *typeEnv) conversion {
+	srcName := env.typeString(srcType)
+	dstName := env.typeString(dstType)
+	funcName := getStructCopyFuncName(srcName, dstName) + opts.suffix()
+	conv := conversion{Func: funcName, IsStruct: true, Opts: opts.suffix()}
+
+	// 防止死循环的。递归类型在生成完之前按可能失败处理，外层也就一定是可能失败的版本
+	key := helperKey(srcType, dstType, opts, mode)
+	fallibleConv := conv
+	if mode != errIgnore {
+		fallibleConv = conversion{Func: funcName + mode.suffix(), IsStruct: true, Fallible: true, ErrSep: errSepStruct, Opts: opts.suffix()}
 	}
-	// 防止死循环的。
-	if _, loaded := generatedStructPairs.LoadOrStore(key, true); loaded {
-		return
-	}
-	funcName := getStructCopyFuncName(srcType, dstType)
-	if _, ok := generatedFunctions.Load(funcName); ok {
-		return
+	if actual, ok := env.scope.structPair(key); ok {
+		return actual
 	}
+	env.scope.structPairs[key] = fallibleConv
 
-	// 新增过滤逻辑：跳过基本类型和外部包类型
-	if isBasicType(srcType) || isBasicType(dstType) {
-		return
+	if !isStructType(srcType) || !isStructType(dstType) {
+		return conv
 	}
 
-	if isExternalType(srcType, file) || isExternalType(dstType, file) {
-		return
+	fields, _ := getFieldMappings(srcType, dstType, env, opts, mode, nil, nil, fieldIgnores{}, exprScope{})
+	funcMode := errIgnore
+	for _, f := range fields {
+		if f.Fallible {
+			conv, funcMode = fallibleConv, mode
+			break
+		}
 	}
-
-	// // 新增：跳过非结构体类型
-	// if !isStructType(srcType, file) || !isStructType(dstType, file) {
-	// 	return
-	// }
-
-	srcStruct := findStructDef(srcType, file, path)
-	dstStruct := findStructDef(dstType, file, path)
-	if srcStruct == nil || dstStruct == nil {
-		return
+	env.scope.structPairs[key] = conv
+	if env.scope.hasHelper(conv.Func) {
+		return conv
 	}
 
-	// 递归处理所有嵌套类型
-	processNestedTypes(srcStruct, file, path)
-	processNestedTypes(dstStruct, file, path)
-
-	fields := getFieldMappings(srcType, dstType, file, false, false, false, nil, path)
-
-	funcDecl := &ast.FuncDecl{
-		Name: ast.NewIdent(funcName),
-		Type: &ast.FuncType{
-			Params: &ast.FieldList{
-				List: []*ast.Field{
-					{Names: []*ast.Ident{ast.NewIdent("dst")}, Type: &ast.StarExpr{X: ast.NewIdent(dstType)}},
-					{Names: []*ast.Ident{ast.NewIdent("src")}, Type: &ast.StarExpr{X: ast.NewIdent(srcType)}},
-				},
-			},
-		},
+	funcDecl := newCopyFuncDecl(conv.Func, "dst", "src", dstName, srcName, conv.Fallible)
+	if err := generateCompleteCopyFunc(funcDecl, "src", "dst", srcName, dstName, fields, funcMode, env); err != nil {
+		env.errorf(token.NoPos, "generate %s: %v", conv.Func, err)
+		return conv
 	}
-	generateCompleteCopyFunc(funcDecl, "src", "dst", srcType, dstType, fields)
 	// 注册生成的函数
-	importPath := []string{}
-	for _, field := range fields {
-		if field.ImportPath != "" {
-			importPath = append(importPath, field.ImportPath)
-		}
-	}
-	addRequiredImports(file, importPath...)
-	generatedFunctions.Store(funcName, funcDecl)
+	env.scope.addHelper(conv.Func, funcDecl)
+	return conv
 }
 
 const copyFuncTemplate = `// {{.FuncName}} 是一个自动生成的拷贝函数
-func {{.FuncName}}({{.DstVar}} *{{.DstType}}, {{.SrcVar}} *{{.SrcType}}) {
+func {{.FuncName}}({{.DstVar}} *{{.DstType}}, {{.SrcVar}} *{{.SrcType}}){{if .ReturnErr}} error{{end}} {
+{{- if .ErrDecl }}
+    {{.ErrDecl}}
+{{- end }}
 {{- range .Fields }}
-{{- if .IsSlice }}
-    // 处理切片字段 {{.DstField}}
+{{- if .SrcNilChecks }}
+    if {{range $i, $p := .SrcNilChecks}}{{if $i}} && {{end}}{{$.SrcVar}}.{{$p}} != nil{{end}} {
+{{- end }}
+{{- range .DstAllocs }}
+    if {{$.DstVar}}.{{.Path}} == nil {
+        {{$.DstVar}}.{{.Path}} = new({{.Type}})
+    }
+{{- end }}
+{{- if .Expr }}
+    // 表达式规则 {{.DstField}}
+    {{$.DstVar}}.{{.DstField}} = {{.Expr}}
+{{- else if .IsSlice }}
+    // 整体转换非结构体类型
+    {{if .Fallible -}}
+	if *{{$.DstVar}}, err = {{.Conversion}}(*{{$.SrcVar}}); err != nil {
+		{{.OnErr}}
+	}
+    {{- else if .Conversion -}}
 	*{{$.DstVar}} = {{.Conversion}}(*{{$.SrcVar}})
-{{- else if .IsEmbedded }}
-    // 处理嵌入字段 {{.DstField}}
-    {{if .ConversionFunc -}}
-    {{.ConversionFunc}}({{$.DstVar}}.{{.DstField}}, {{$.SrcVar}}.{{.SrcField}})
     {{- else -}}
-    {{$.DstVar}}.{{.DstField}} = {{$.SrcVar}}.{{.SrcField}} // 默认直接拷贝
+	*{{$.DstVar}} = *{{$.SrcVar}}
+    {{- end}}
+{{- else if .IsStruct }}
+    // 结构体字段 {{.DstField}}
+    {{if .Fallible -}}
+	if err = {{.Conversion}}(&{{$.DstVar}}.{{.DstField}}, &{{$.SrcVar}}.{{.SrcField}}); err != nil {
+		{{.OnErr}}
+	}
+    {{- else -}}
+    {{.Conversion}}(&{{$.DstVar}}.{{.DstField}}, &{{$.SrcVar}}.{{.SrcField}})
     {{- end}}
 {{- else if .Conversion }}
     // 类型转换字段 {{.DstField}}
+    {{if .Fallible -}}
+	if {{$.DstVar}}.{{.DstField}}, err = {{.Conversion}}({{$.SrcVar}}.{{.SrcField}}); err != nil {
+		{{.OnErr}}
+	}
+    {{- else -}}
     {{$.DstVar}}.{{.DstField}} = {{.Conversion}}({{$.SrcVar}}.{{.SrcField}})
+    {{- end}}
 {{- else }}
     // 直接赋值字段 {{.DstField}}
     {{$.DstVar}}.{{.DstField}} = {{$.SrcVar}}.{{.SrcField}}
 {{- end }}
+{{- if .SrcNilChecks }}
+    }
+{{- end }}
+{{- end }}
+{{- if .ReturnErr }}
+    return {{.ReturnErr}}
 {{- end }}
 }`
 
-func addGeneratedFunction(funcName string, fn *ast.FuncDecl) {
-	log.Printf("Adding generated function: %s", funcName)
-	generatedFunctions.Store(funcName, fn)
+func addGeneratedFunction(funcName string, fn *ast.FuncDecl, env *typeEnv) {
+	env.debugf("Adding generated function: %s", funcName)
+	clearPositions(fn)
+	env.scope.addHelper(funcName, fn)
 }
 
-// parseFieldMappings 解析字段映射规则
-func parseFieldMappings(comment string) map[string]string {
-	mappings := make(map[string]string)
-	// 提取映射规则部分
-	start := strings.Index(comment, "// :quickcopy")
-	if start == -1 {
-		return mappings
-	}
-	// 去掉注释前缀
-	rulePart := strings.TrimSpace(comment[start+len("// :quickcopy"):])
-	// 按逗号分割规则
-	rules := strings.Split(rulePart, ",")
-	for _, rule := range rules {
-		rule = strings.TrimSpace(rule)
-		if rule == "" {
-			continue
-		}
-		// 解析 dstField = srcField
-		parts := strings.Split(rule, "=")
-		if len(parts) != 2 {
-			log.Printf("Invalid mapping rule: %s", rule)
-			continue
-		}
-		dstField := strings.TrimSpace(parts[0])
-		srcField := strings.TrimSpace(parts[1])
-
-		// 存储完整的字段路径
-		mappings[dstField] = srcField
-	}
-	return mappings
-}
+// copyFuncTmpl 是解析好的 copyFuncTemplate
+var copyFuncTmpl = template.Must(template.New("copyFunc").Parse(copyFuncTemplate))
 
 // generateCompleteCopyFunc 生成完整的拷贝函数并替换原始函数
-func generateCompleteCopyFunc(funcDecl *ast.FuncDecl, srcVar, dstVar, srcType, dstType string, fields []FieldMapping) {
-	// 生成拷贝函数代码
-	tmpl, err := template.New("copyFunc").Parse(copyFuncTemplate)
-	if err != nil {
-		log.Fatalf("Failed to parse template: %v", err)
-	}
-
-	var code bytes.Buffer
-	err = tmpl.Execute(&code, CopyFuncInfo{
+func generateCompleteCopyFunc(funcDecl *ast.FuncDecl, srcVar, dstVar, srcType, dstType string, fields []FieldMapping, mode errMode, env *typeEnv) error {
+	info := CopyFuncInfo{
 		FuncName: funcDecl.Name.Name,
 		SrcVar:   srcVar,
 		DstVar:   dstVar,
 		SrcType:  srcType,
 		DstType:  dstType,
 		Fields:   fields,
-	})
-	if err != nil {
-		log.Fatalf("Failed to execute template: %v", err)
+	}
+	if mode != errIgnore {
+		info.ReturnErr = "nil"
+		for _, f := range fields {
+			if f.Fallible {
+				info.ErrDecl = mode.errDecl()
+				info.ReturnErr = mode.returnErr(env)
+				break
+			}
+		}
+	}
+
+	// 生成拷贝函数代码
+	var code bytes.Buffer
+	if err := copyFuncTmpl.Execute(&code, info); err != nil {
+		return fmt.Errorf("execute template: %w", err)
 	}
 
 	// 将生成的代码包装在一个完整的 Go 文件中
@@ -371,7 +317,7 @@ func generateCompleteCopyFunc(funcDecl *ast.FuncDecl, srcVar, dstVar, srcType, d
 	fset := token.NewFileSet()
 	block, err := parser.ParseFile(fset, "", wrappedCode, parser.ParseComments)
 	if err != nil {
-		log.Fatalf("Failed to parse generated code: %v, %s", err, wrappedCode)
+		return fmt.Errorf("parse generated code: %w\n%s", err, wrappedCode)
 	}
 
 	// 提取生成的函数声明
@@ -380,203 +326,157 @@ func generateCompleteCopyFunc(funcDecl *ast.FuncDecl, srcVar, dstVar, srcType, d
 	// 将原始函数的注释附加到新生成的函数上
 	if funcDecl.Doc != nil {
 		newFuncDecl.Doc = funcDecl.Doc
-		fmt.Printf("Attached doc to new function: %s, comment: %s\n", newFuncDecl.Name.Name, funcDecl.Doc.Text())
+		env.debugf("Attached doc to new function: %s, comment: %s", newFuncDecl.Name.Name, funcDecl.Doc.Text())
+	} else {
+		// 生成的辅助函数使用模板里的注释
+		funcDecl.Doc = newFuncDecl.Doc
 	}
 
+	// 沿用原函数体的花括号位置，函数体内部不保留位置信息
+	clearPositions(newFuncDecl.Body)
+	if funcDecl.Body != nil {
+		newFuncDecl.Body.Lbrace = funcDecl.Body.Lbrace
+		newFuncDecl.Body.Rbrace = funcDecl.Body.Rbrace
+	}
 	funcDecl.Body = newFuncDecl.Body
+	return nil
 }
 
-// writeFile 将修改后的 AST 写回文件
-func writeFile(fset *token.FileSet, file *ast.File, path string) {
-	var existingFuncs Map[string, int]
-	// 构建现有函数索引（名称 -> 声明位置）
-	for i, decl := range file.Decls {
-		if fn, ok := decl.(*ast.FuncDecl); ok {
-			fmt.Printf("Found existing function: %s\n", fn.Name.Name)
-			existingFuncs.Store(fn.Name.Name, i)
-		}
-	}
-
-	// 合并生成的函数
-	generatedFunctions.Range(func(_ string, newFn *ast.FuncDecl) bool {
-		name := newFn.Name.Name
-		log.Printf("Processing function: %s", name)
-		if idx, exists := existingFuncs.Load(name); exists {
-			// 替换已存在的函数声明
-			file.Decls[idx] = newFn
-		} else {
-			// 追加新的函数声明
-			file.Decls = append(file.Decls, newFn)
-		}
-		return true
-	})
-
-	// 清空注册表
-	generatedFunctions.Clear()
-
-	// 创建输出文件
-	outputFile, err := os.Create(path)
-	if err != nil {
-		log.Fatalf("Failed to create output file %s: %v", path, err)
-	}
-	defer outputFile.Close()
-
-	// 格式化整个文件
-	var buf bytes.Buffer
-
-	// cfg := &printer.Config{}
-	cfg := &printer.Config{Mode: printer.TabIndent, Tabwidth: 8}
-	cfg.Fprint(&buf, fset, file)
-	// 将格式化后的内容写入文件
-	_, err = outputFile.Write(buf.Bytes())
+// writeFile 渲染修改后的文件，交给 res 决定写回磁盘还是只做比较
+func (s *session) writeFile(src *sourceFile, env *typeEnv, helpers map[string]bool) {
+	content, err := renderFile(src, env.importList(), helpers)
 	if err != nil {
-		log.Fatalf("Failed to write file: %v", err)
+		s.res.errorf(token.Position{Filename: src.path}, "render file: %v", err)
+		return
 	}
 
-	log.Printf("Successfully updated and formatted file: %s", path)
+	s.res.write(src.path, content)
+	s.debugf("Successfully updated and formatted file: %s", src.path)
 }
 
-// getFieldMappings 获取字段映射关系，支持结构体内嵌
-func getFieldMappings(srcType, dstType string, file *ast.File, ignoreCase, allowNarrow, singleToSlice bool, fieldMappings map[string]string, path string) []FieldMapping {
-	if isSliceOrArray(srcType) && isSliceOrArray(dstType) {
-		srcElem := getElementType(srcType)
-		dstElem := getElementType(dstType)
-		conversion, _ := getTypeConversion(srcElem, dstElem, allowNarrow, singleToSlice, file, path)
-		if conversion != "" {
-			sliceConv, sliceImportPath := generateSliceCopyFunc(srcElem, dstElem, conversion, file, path)
-			return []FieldMapping{
-				{
-					SrcField:   "",
-					DstField:   "",
-					Conversion: sliceConv,
-					IsSlice:    true,
-					ImportPath: sliceImportPath,
-				},
-			}
+// getFieldMappings 获取字段映射关系，支持结构体内嵌。
+// 第二个返回值是两边没有对应关系、也没有被忽略的字段
+func getFieldMappings(srcType, dstType types.Type, env *typeEnv, opts copyOptions, mode errMode, rules []mappingRule, nilDefaults map[string]string, ignores fieldIgnores, scope exprScope) ([]FieldMapping, fieldCoverage) {
+	// 顶层的切片、数组和 map 等非结构体类型整体转换
+	if !isStructType(srcType) || !isStructType(dstType) {
+		conv, ok := getTypeConversion(srcType, dstType, opts, mode, env)
+		if ok && !conv.IsStruct {
+			m := newFieldMapping("", "", conv, mode, env)
+			m.IsSlice = true
+			return []FieldMapping{m}, fieldCoverage{}
 		}
-		return nil
+		return nil, fieldCoverage{}
 	}
 
-	// 生成内层结构体的拷贝函数（如果存在）
-	generateCopyFunctionIfNeeded(srcType, dstType, file, path)
 	var fields []FieldMapping
 
 	// 查找源类型和目标类型的结构体定义
-	srcStruct := findStructDef(srcType, file, path)
-	dstStruct := findStructDef(dstType, file, path)
+	srcStruct := findStructDef(srcType)
+	dstStruct := findStructDef(dstType)
 
 	if srcStruct == nil || dstStruct == nil {
-		log.Printf("Failed to find struct definitions for %s or %s", srcType, dstType)
-		return fields
+		env.warnf(token.NoPos, "cannot find struct definitions for %s or %s", env.typeString(srcType), env.typeString(dstType))
+		return fields, fieldCoverage{}
 	}
 
-	log.Printf("Found struct definitions: %s and %s", srcType, dstType)
+	env.debugf("Found struct definitions: %s and %s", env.typeString(srcType), env.typeString(dstType))
 
-	// 用于记录已经映射的目标字段
+	// 用于记录已经映射的目标字段，忽略的字段当作已经映射
 	mappedDstFields := make(map[string]bool)
-
-	// 如果有显式的字段映射规则，则按照规则进行映射
-	for dstFieldPath, srcFieldPath := range fieldMappings {
-		// 查找目标字段
-		dstFieldName := extractFieldName(dstFieldPath)
-		dstField := findFieldByName(dstStruct, dstFieldName, file, path)
-		if dstField == nil {
-			log.Printf("Destination field not found: %s", dstFieldName)
-			continue
+	for _, name := range byPos(ignores.dst) {
+		if field, _ := findFieldByName(dstStruct, name, env); field == nil {
+			env.warnf(ignores.dst[name], "ignored destination field not found: %s", name)
 		}
-
-		// 查找源字段
-		srcFieldName := extractFieldName(srcFieldPath)
-		srcField := findFieldByName(srcStruct, srcFieldName, file, path)
-		if srcField == nil {
-			log.Printf("Source field not found: %s", srcFieldName)
-			continue
+		mappedDstFields[name] = true
+	}
+	for _, name := range byPos(ignores.src) {
+		if field, _ := findFieldByName(srcStruct, name, env); field == nil {
+			env.warnf(ignores.src[name], "ignored source field not found: %s", name)
 		}
-
-		// 获取类型转换逻辑
-		conversion, importPath := getTypeConversion(types.ExprString(srcField.Type), types.ExprString(dstField.Type), allowNarrow, singleToSlice, file, path)
-
-		// 判断是否为嵌入字段
-		isEmbedded := isEmbeddedField(srcField) || isEmbeddedField(dstField)
-		// 存储映射关系
-		fields = append(fields, FieldMapping{
-			SrcField:       srcFieldPath, // 使用完整的源字段路径
-			DstField:       dstFieldPath, // 使用完整的目标字段路径
-			Conversion:     conversion,
-			IsEmbedded:     isEmbedded,
-			ConversionFunc: getStructCopyFuncName(srcType, dstType),
-			ImportPath:     importPath,
-		})
-		log.Printf("Mapped field: %s -> %s (Conversion: %s)", srcFieldPath, dstFieldPath, conversion)
-
-		// 标记该目标字段已经映射
-		mappedDstFields[dstFieldName] = true
 	}
 
-	// 处理目标结构体的字段
-	processFields(dstStruct, srcStruct, file, "", false, ignoreCase, allowNarrow, singleToSlice, &fields, mappedDstFields, path)
-	return fields
-}
-
-// 新增辅助函数：判断字段是否为嵌入字段
-func isEmbeddedField(field *ast.Field) bool {
-	return len(field.Names) == 0
-}
+	// 规则使用的源字段，多级路径记录第一级
+	usedSrcFields := make(map[string]bool)
+	badRules := false
 
-// findFieldByName 在结构体中查找字段，支持内嵌结构体
-func findFieldByName(structType *ast.StructType, fieldName string, file *ast.File, path string) *ast.Field {
-	for _, field := range structType.Fields.List {
-		// 处理内嵌结构体
-		if len(field.Names) == 0 {
-			// 内嵌结构体
-			embeddedType := types.ExprString(field.Type)
-			// 关键修改：传递当前文件的 AST 节点
-			embeddedStruct := findStructDef(embeddedType, file, path)
-			if embeddedStruct != nil {
-				// 递归查找内嵌结构体的字段
-				if foundField := findFieldByName(embeddedStruct, fieldName, file, path); foundField != nil {
-					return foundField
-				}
-			}
+	// 如果有显式的字段映射规则，则按照规则进行映射，生成的代码和指令中的声明顺序一致
+	for _, rule := range rules {
+		dstFieldPath, srcFieldPath := rule.dst, rule.src
+		// 查找目标字段，路径可以有多级，如 Address.City
+		dst, err := resolveFieldPath(dstStruct, dstFieldPath, env)
+		if err != nil {
+			env.warnf(rule.pos, "destination field %s: %v", dstFieldPath, err)
 			continue
 		}
 
-		// 处理普通字段
-		for _, name := range field.Names {
-			if name.Name == fieldName {
-				return field
-			}
+		var dstAllocs []PathAlloc
+		for i, p := range dst.ptrs {
+			dstAllocs = append(dstAllocs, PathAlloc{Path: p, Type: env.typeString(dst.ptrElems[i])})
 		}
-	}
-	return nil
-}
 
-// findFieldByNameIgnoreCase 在结构体中查找字段（忽略大小写），支持内嵌结构体
-func findFieldByNameIgnoreCase(structType *ast.StructType, fieldName string, file *ast.File, path string) (*ast.Field, string) {
-	for _, field := range structType.Fields.List {
-		// 处理内嵌结构体
-		if len(field.Names) == 0 {
-			// 内嵌结构体
-			embeddedType := types.ExprString(field.Type)
-			// 关键修改：传递当前文件的 AST 节点
-			embeddedStruct := findStructDef(embeddedType, file, path)
-			if embeddedStruct != nil {
-				// 递归查找内嵌结构体的字段
-				if foundField, foundName := findFieldByNameIgnoreCase(embeddedStruct, fieldName, file, path); foundField != nil {
-					return foundField, foundName
+		// 查找源字段，右边不是字段路径（src.Name 也按表达式处理）或者找不到这个字段时按表达式处理，
+		// 如 src.FirstName + " " + src.LastName、"api"、StatusActive
+		src, err := fieldPath{}, errNotFieldPath
+		if isFieldPath(srcFieldPath) && !strings.HasPrefix(srcFieldPath, scope.srcVar+".") {
+			src, err = resolveFieldPath(srcStruct, srcFieldPath, env)
+		}
+		if err != nil {
+			expr, used, exprErr := checkRuleExpr(dstFieldPath, srcFieldPath, dstType, srcType, rule.pos, scope, env)
+			// 规则写错时不能退回按名字匹配，否则生成的代码可以编译但结果不对
+			if exprErr != nil {
+				if err != errNotFieldPath {
+					env.errorf(rule.pos, "source field %s: %v", srcFieldPath, err)
+				} else {
+					env.errorf(rule.pos, "mapping rule %s = %s: %v", dstFieldPath, srcFieldPath, exprErr)
 				}
+				mappedDstFields[dst.top] = true
+				badRules = true
+				continue
+			}
+			fields = append(fields, FieldMapping{DstField: dstFieldPath, Expr: expr, DstAllocs: dstAllocs})
+			env.debugf("Mapped expression: %s -> %s", srcFieldPath, dstFieldPath)
+			mappedDstFields[dst.top] = true
+			for _, name := range used {
+				usedSrcFields[name] = true
 			}
 			continue
 		}
 
-		// 处理普通字段
-		for _, name := range field.Names {
-			if strings.EqualFold(name.Name, fieldName) {
-				return field, name.Name
-			}
+		// 获取类型转换逻辑
+		conv, ok := fieldConversion(src.field, dst.field, src.tag, dst.tag, nilDefaults[dstFieldPath], opts, mode, env)
+		if !ok {
+			env.warnf(rule.pos, "cannot convert %s to %s", srcFieldPath, dstFieldPath)
+			continue
 		}
+
+		// 存储映射关系
+		// 使用完整的源字段路径和目标字段路径，源路径上的指针为 nil 时不拷贝，目标路径上的指针为 nil 时分配
+		m := newFieldMapping(srcFieldPath, dstFieldPath, conv, mode, env)
+		m.SrcNilChecks = src.ptrs
+		m.DstAllocs = dstAllocs
+		fields = append(fields, m)
+		env.debugf("Mapped field: %s -> %s (Conversion: %s)", srcFieldPath, dstFieldPath, conv.Func)
+
+		// 标记该目标字段已经映射，多级路径标记第一级，避免整体拷贝覆盖规则的结果；
+		// 内嵌结构体按提升后的字段记录
+		mappedDstFields[dst.top] = true
+		usedSrcFields[src.top] = true
+	}
+
+	// 处理目标结构体的字段
+	processFields(dstStruct, srcStruct, "", nil, opts, mode, nilDefaults, &fields, mappedDstFields, env)
+	// 显式规则和 processFields 都处理完之后，剩下的就是没有对应关系的字段
+	for name := range ignores.src {
+		usedSrcFields[name] = true
+	}
+	for _, f := range fields {
+		usedSrcFields[topField(f.SrcField)] = true
+	}
+	return fields, fieldCoverage{
+		unmappedDst: unmappedFields(dstStruct, mappedDstFields, env),
+		unusedSrc:   unmappedFields(srcStruct, usedSrcFields, env),
+		badRules:    badRules,
 	}
-	return nil, ""
 }
 
 // 新增函数：获取切片拷贝函数名
@@ -586,255 +486,208 @@ func getSliceCopyFuncName(srcElem, dstElem string) string {
 		sanitizeTypeName(srcElem))
 }
 
-// 修改 getTypeConversion 函数签名，增加 file 参数
-func getTypeConversion(srcType, dstType string, allowNarrow, singleToSlice bool, file *ast.File, path string) (string, string) {
-	// 类型相同无需转换
-	if srcType == dstType {
-		return "", ""
+// getTypeConversion 根据 go/types 的类型信息决定源类型到目标类型的转换方式，
+// 第二个返回值为 false 表示无法转换
+func getTypeConversion(srcType, dstType types.Type, opts copyOptions, mode errMode, env *typeEnv) (conversion, bool) {
+	// 用户注册的转换函数优先于内置转换
+	if conv, ok := env.lookupConverter(srcType, dstType, mode); ok {
+		return conv, true
+	}
+
+	// 可以直接赋值的类型无需转换
+	if types.AssignableTo(srcType, dstType) {
+		return conversion{}, true
 	}
 
-	if isSliceOrArray(srcType) && isSliceOrArray(dstType) {
-		return handleSliceConversion(srcType, dstType, allowNarrow, singleToSlice, file, path)
+	// 底层类型相同的具名切片、数组、map（如 type Hash [16]byte）直接做类型转换
+	if !isStructType(srcType) && types.Identical(srcType.Underlying(), dstType.Underlying()) {
+		return conversion{Func: env.typeString(dstType)}, true
+	}
+
+	if isSliceType(srcType) && isSliceType(dstType) {
+		return handleSliceConversion(srcType, dstType, opts, mode, env)
+	}
+	if (isArrayType(srcType) || isSliceType(srcType)) && (isArrayType(dstType) || isSliceType(dstType)) {
+		return handleArrayConversion(srcType, dstType, opts, mode, env)
+	}
+	if isMapType(srcType) && isMapType(dstType) {
+		return handleMapConversion(srcType, dstType, opts, mode, env)
 	}
 	// 处理基本类型转换
 	if isBasicType(srcType) && isBasicType(dstType) {
-		return handleBasicConversion(srcType, dstType, allowNarrow)
+		return handleBasicConversion(srcType, dstType, opts.allowNarrow, mode, env)
 	}
 	// 处理结构体类型
-	if isStructType(srcType, file, path) && isStructType(dstType, file, path) {
-		return generateStructConversionFunc(srcType, dstType), ""
+	if isStructType(srcType) && isStructType(dstType) {
+		return handleStructConversion(srcType, dstType, opts, mode, env), true
 	}
 
 	// 处理指针类型
 	if isPointerType(srcType) && isPointerType(dstType) {
-		return handlePointerConversion(srcType, dstType, allowNarrow, singleToSlice, file, path)
-	}
-
-	// 其他类型转换逻辑
-	return handleSpecialTypeConversion(srcType, dstType)
-}
-
-// isStructType 判断给定类型是否为结构体类型（包含指针类型和跨包类型）
-func isStructType(typeName string, file *ast.File, path string) bool {
-	if typeName == "time.Time" {
-		return false
-	}
-	// 快速跳过基本类型和复合类型
-	if isBasicType(typeName) || isSliceOrArray(typeName) || isPointerType(typeName) {
-		return false
+		return handlePointerConversion(srcType, dstType, opts, mode, env)
 	}
-
-	// 处理指针类型（递归判断底层类型）
-	if strings.HasPrefix(typeName, "*") {
-		return isStructType(strings.TrimPrefix(typeName, "*"), file, path)
-	}
-
-	// 处理数组/切片前缀（递归判断元素类型）
-
-	if strings.HasPrefix(typeName, "[]") || strings.Contains(typeName, "[") {
-		elemType := getElementType(typeName)
-		return isStructType(elemType, file, path)
+	if isPointerType(srcType) {
+		return handleDerefConversion(srcType, dstType, "", opts, mode, env)
 	}
-
-	// 分解包前缀（处理形如 pkg.Struct 的类型）
-	pkgPath, typeName := parsePkgType(typeName)
-
-	// 在当前文件的 imports 中查找匹配的包路径
-	if pkgPath != "" {
-		for _, imp := range file.Imports {
-			importedPath := strings.Trim(imp.Path.Value, `"`)
-			if importedPath == pkgPath ||
-				(imp.Name != nil && imp.Name.Name == pkgPath) {
-				return findStructDefInPackage(importedPath, typeName) != nil
-			}
-		}
+	if isPointerType(dstType) {
+		return handleAddrConversion(srcType, dstType, opts, mode, env)
 	}
 
-	// 查找本地结构体定义
-	return findStructDef(typeName, file, path) != nil
+	// 其他类型转换逻辑
+	return handleSpecialConversion(srcType, dstType, mode, env)
 }
 
 // 核心处理函数
-func handleBasicConversion(src, dst string, allowNarrow bool) (string, string) {
+func handleBasicConversion(src, dst types.Type, allowNarrow bool, mode errMode, env *typeEnv) (conversion, bool) {
 	// 整数类型转换
 	if isIntegerType(src) && isIntegerType(dst) {
 		srcWidth := getIntWidth(src)
 		dstWidth := getIntWidth(dst)
 
 		if srcWidth > dstWidth && !allowNarrow {
-			log.Printf("Narrowing conversion disabled: %s -> %s", src, dst)
-			return "", ""
+			env.debugf("Narrowing conversion disabled: %s -> %s", env.typeString(src), env.typeString(dst))
+			return conversion{}, false
 		}
-		return dst, "" // 返回类型名称作为转换函数
+		return conversion{Func: env.typeString(dst)}, true // 返回类型名称作为转换函数
+	}
+
+	// 底层类型相同的具名类型（如 type Status string）直接做类型转换
+	if types.Identical(src.Underlying(), dst.Underlying()) {
+		return conversion{Func: env.typeString(dst)}, true
 	}
 
 	// 其他基本类型转换
-	return handleSpecialTypeConversion(src, dst)
+	return handleSpecialConversion(src, dst, mode, env)
 }
 
-func generateElementConversion(srcVar, dstVar, conversion string) string {
-	if conversion == "" {
-		return fmt.Sprintf("%s = %s", dstVar, srcVar)
+// handleStructConversion 返回结构体之间的拷贝函数，必要时生成
+func handleStructConversion(src, dst types.Type, opts copyOptions, mode errMode, env *typeEnv) conversion {
+	env = env.helperEnv()
+	// 已经有错误处理方式相同的顶层拷贝函数时直接复用
+	if funcName, ok := env.scope.topLevelFunc(helperKey(src, dst, opts, mode)); ok {
+		if mode != errIgnore {
+			return conversion{Func: funcName, IsStruct: true, Fallible: true, ErrSep: errSepStruct, Opts: opts.suffix()}
+		}
+		return conversion{Func: funcName, IsStruct: true, Opts: opts.suffix()}
 	}
+	return generateCopyFunctionIfNeeded(src, dst, opts, mode, env)
+}
 
-	// 处理结构体转换
-	if strings.HasPrefix(conversion, "func(") {
-		funcName := getStructCopyFuncName(
-			strings.TrimPrefix(getElementType(srcVar), "*"),
-			strings.TrimPrefix(getElementType(dstVar), "*"),
-		)
-		return fmt.Sprintf("%s(&%s, &%s)", funcName, dstVar, srcVar)
+// generateElementConversion 生成把 srcVar 转换后赋值给 dstVar 的语句
+func generateElementConversion(srcVar, dstVar string, conv conversion) string {
+	if conv.IsStruct {
+		return fmt.Sprintf("%s(&%s, &%s)", conv.Func, dstVar, srcVar)
+	}
+	if conv.Func == "" {
+		return fmt.Sprintf("%s = %s", dstVar, srcVar)
 	}
 
 	// 基本类型转换
-	return fmt.Sprintf("%s = %s(%s)", dstVar, conversion, srcVar)
+	return fmt.Sprintf("%s = %s(%s)", dstVar, conv.Func, srcVar)
 }
 
 func getStructCopyFuncName(src, dst string) string {
-	// if src == dst {
-	// 	return "" // 相同类型不需要转换函数
-	// }
 	srcClean := sanitizeTypeName(src)
 	dstClean := sanitizeTypeName(dst)
 	return fmt.Sprintf("copy%sFrom%s", dstClean, srcClean)
 }
 
-func generateStructConversionFunc(src, dst string) string {
-	funcName := getStructCopyFuncName(src, dst)
-	return fmt.Sprintf(`%s(dst *%s, src *%s) {
-        // Auto-generated struct copy
-        // Implement field mappings here
-    }`, funcName, dst, src)
-}
-
-// 新增指针类型判断函数
-func isPointerType(typeName string) bool {
-	return strings.HasPrefix(typeName, "*")
-}
-
-// 新增指针转换处理函数
-func handlePointerConversion(srcType, dstType string, allowNarrow, singleToSlice bool, file *ast.File, path string) (string, string) {
-	// 获取基础类型
-	baseSrc := strings.TrimPrefix(srcType, "*")
-	baseDst := strings.TrimPrefix(dstType, "*")
-
-	// 递归获取基础类型转换
-	baseConv, importPath := getTypeConversion(baseSrc, baseDst, allowNarrow, singleToSlice, file, path)
-
-	// 生成指针转换逻辑
-	return fmt.Sprintf(`func(src %s) %s {
-        if src == nil {
-            return nil
-        }
-        dst := new(%s)
-        %s
-        return dst
-    }`, srcType, dstType, baseDst, generateElementConversion("*src", "dst", baseConv)), importPath
+// derefType 去掉一层指针
+func derefType(t types.Type) types.Type {
+	if ptr, ok := t.(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
 }
 
-func Main(dir string) {
-	// 要遍历的目录
-	if dir == "" {
-		dir = "." // 当前目录
+// newCopyFuncDecl 创建拷贝函数的声明，函数体由 generateCompleteCopyFunc 填充
+func newCopyFuncDecl(name, dstVar, srcVar, dstType, srcType string, fallible bool) *ast.FuncDecl {
+	funcDecl := &ast.FuncDecl{
+		Name: ast.NewIdent(name),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: []*ast.Field{
+					{Names: []*ast.Ident{ast.NewIdent(dstVar)}, Type: &ast.StarExpr{X: ast.NewIdent(dstType)}},
+					{Names: []*ast.Ident{ast.NewIdent(srcVar)}, Type: &ast.StarExpr{X: ast.NewIdent(srcType)}},
+				},
+			},
+		},
+	}
+	if fallible {
+		funcDecl.Type.Results = &ast.FieldList{List: []*ast.Field{{Type: ast.NewIdent("error")}}}
 	}
+	return funcDecl
+}
 
-	// 遍历目录
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			log.Printf("Error accessing path %s: %v", path, err)
-			return err
+// generateTopLevelFunc 根据拷贝函数的签名和指令生成函数体，返回函数声明以及字段映射。
+// funcDecl 为 nil 时（var _ 声明的原型）新建函数声明
+func generateTopLevelFunc(funcDecl *ast.FuncDecl, name string, sig *types.Signature, d *directive, env *typeEnv) (*ast.FuncDecl, []FieldMapping, error) {
+	params := sig.Params()
+	if params.Len() != 2 {
+		return nil, nil, fmt.Errorf("copy function %s must have exactly two parameters", name)
+	}
+	// 返回 error 时检查所有可能失败的转换
+	mode, ok := errorMode(sig, d.joinErrors)
+	if !ok {
+		return nil, nil, fmt.Errorf("copy function %s must return nothing or error", name)
+	}
+	if d.joinErrors && mode == errIgnore {
+		env.warnf(token.NoPos, "copy function %s: --join-errors requires an error result", name)
+	}
+	// 写错的选项（如 --strictt）不能静默忽略，否则生成的代码和预期不同
+	if len(d.problems) > 0 {
+		for _, problem := range d.problems {
+			env.errorf(problem.pos, "%s", problem.msg)
 		}
+		return nil, nil, fmt.Errorf("copy function %s: invalid // :quickcopy directive", name)
+	}
 
-		// 只处理 Go 文件
-		if !info.IsDir() && strings.HasSuffix(info.Name(), ".go") {
-			log.Printf("Processing file: %s", path)
-
-			// 解析文件
-			fset := token.NewFileSet()
-			file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
-			if err != nil {
-				log.Printf("Failed to parse file %s: %v", path, err)
-				return nil
-			}
-
-			// 查找带有 // :quickcopy 注释的函数
-			ast.Inspect(file, func(n ast.Node) bool {
-				// 查找函数声明
-				funcDecl, ok := n.(*ast.FuncDecl)
-				if !ok || funcDecl.Doc == nil {
-					return true
-				}
-
-				// 检查是否有 // :quickcopy 注释
-				var isQuickCopy bool
-				var allowNarrow bool
-				var ignoreCase bool
-				var singleToSlice bool
-				var fieldMappings map[string]string // 存储字段映射规则
-				for _, comment := range funcDecl.Doc.List {
-					if strings.Contains(comment.Text, "// :quickcopy") {
-						isQuickCopy = true
-						// 解析选项
-						if strings.Contains(comment.Text, "--allow-narrow") {
-							allowNarrow = true
-						}
-						if strings.Contains(comment.Text, "--ignore-case") {
-							ignoreCase = true
-						}
-						if strings.Contains(comment.Text, "--single-to-slice") {
-							singleToSlice = true
-						}
-						// 解析字段映射规则
-						fieldMappings = parseFieldMappings(comment.Text)
-						break
-					}
-				}
-				if !isQuickCopy {
-					return true
-				}
-
-				log.Printf("Found // :quickcopy function: %s", funcDecl.Name.Name)
-
-				// 解析函数签名
-				if funcDecl.Type.Params.List == nil || len(funcDecl.Type.Params.List) != 2 {
-					log.Fatalf("Copy function %s must have exactly two parameters", funcDecl.Name.Name)
-				}
-
-				dstParam := funcDecl.Type.Params.List[0]
-				srcParam := funcDecl.Type.Params.List[1]
-
-				srcVar := srcParam.Names[0].Name
-				dstVar := dstParam.Names[0].Name
-
-				srcType := strings.TrimPrefix(types.ExprString(srcParam.Type), "*")
-				dstType := strings.TrimPrefix(types.ExprString(dstParam.Type), "*")
+	dstVar := params.At(0).Name()
+	srcVar := params.At(1).Name()
+	// 就地生成的函数体通过参数名读写，var _ 原型的参数名可以省略，生成时使用 dst 和 src
+	if funcDecl != nil && (dstVar == "" || dstVar == "_" || srcVar == "" || srcVar == "_") {
+		return nil, nil, fmt.Errorf("copy function %s must name its parameters, e.g. func %s(dst *D, src *S)", name, name)
+	}
+	if dstVar == "" || dstVar == "_" {
+		dstVar = "dst"
+	}
+	if srcVar == "" || srcVar == "_" {
+		srcVar = "src"
+	}
 
-				log.Printf("Source type: %s, Destination type: %s", srcType, dstType)
+	srcType := derefType(params.At(1).Type())
+	dstType := derefType(params.At(0).Type())
+	srcName := env.typeString(srcType)
+	dstName := env.typeString(dstType)
 
-				processedTopLevelTypes.Store(fmt.Sprintf("%s->%s", srcType, dstType), true)
+	env.debugf("Source type: %s, Destination type: %s", srcName, dstName)
 
-				// 提取字段映射关系
-				fields := getFieldMappings(srcType, dstType, file, ignoreCase, allowNarrow, singleToSlice, fieldMappings, path)
+	env.scope.topLevel[helperKey(srcType, dstType, d.opts, mode)] = name
 
-				importPath := []string{}
-				for _, field := range fields {
-					if field.ImportPath != "" {
-						importPath = append(importPath, field.ImportPath)
-					}
-				}
-				// 生成完整的拷贝函数
-				generateCompleteCopyFunc(funcDecl, srcVar, dstVar, srcType, dstType, fields)
-				// 加入必要的导入
-				addRequiredImports(file, importPath...)
-				// 将修改后的 AST 写回文件
-				writeFile(fset, file, path)
-				return true
-			})
+	// 提取字段映射关系
+	fields, coverage := getFieldMappings(srcType, dstType, env, d.opts, mode, d.fieldMappings, d.nilDefaults, d.ignores, exprScope{dstVar: dstVar, srcVar: srcVar})
+	if coverage.badRules {
+		return nil, nil, fmt.Errorf("copy function %s: invalid mapping rules", name)
+	}
+	if d.strict && len(coverage.unmappedDst) > 0 {
+		return nil, nil, fmt.Errorf("copy function %s: destination fields without source: %s (map them or ignore with -Field)",
+			name, strings.Join(coverage.unmappedDst, ", "))
+	}
+	if len(coverage.unusedSrc) > 0 {
+		msg := fmt.Sprintf("copy function %s: source fields not used: %s (map them or ignore with _ = Field)",
+			name, strings.Join(coverage.unusedSrc, ", "))
+		if d.strictSource {
+			return nil, nil, errors.New(msg)
 		}
+		env.warnf(token.NoPos, "%s", msg)
+	}
 
-		return nil
-	})
-	if err != nil {
-		log.Fatalf("Failed to walk directory: %v", err)
+	if funcDecl == nil {
+		funcDecl = newCopyFuncDecl(name, dstVar, srcVar, dstName, srcName, mode != errIgnore)
+	}
+	// 生成完整的拷贝函数
+	if err := generateCompleteCopyFunc(funcDecl, srcVar, dstVar, srcName, dstName, fields, mode, env); err != nil {
+		return nil, nil, fmt.Errorf("generate %s: %w", name, err)
 	}
+	return funcDecl, fields, nil
 }
@@ -0,0 +1,291 @@
+package quickcopy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Severity 是诊断信息的级别
+type Severity int
+
+const (
+	SeverityWarning Severity = iota // 跳过无法转换的字段等，生成仍然继续
+	SeverityError                   // 拷贝函数或者文件无法生成
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Diagnostic 是生成过程中发现的问题，Pos 指向相关的原型、字段或者指令
+type Diagnostic struct {
+	Pos      token.Position
+	Severity Severity
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	if pos := d.Pos.String(); pos != "-" {
+		return fmt.Sprintf("%s: %s: %s", pos, d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s: %s", d.Severity, d.Message)
+}
+
+// GeneratedFunc 是一个生成的顶层拷贝函数
+type GeneratedFunc struct {
+	Name      string
+	Pos       token.Position // 原型的位置
+	Signature string         // 如 func CopyUser(dst *User, src *UserReq) error
+	Fields    []FieldMapping
+}
+
+// FileResult 是一个生成后的文件
+type FileResult struct {
+	Path    string
+	Content []byte // 生成后的完整内容，渲染失败时为 nil
+	Changed bool   // Content 和磁盘上的内容不同，需要写入
+	Removed bool   // 不再需要的生成文件，Write 时删除，Content 为 nil
+	Funcs   []GeneratedFunc
+}
+
+// Result 是 Generate 的结果，文件按第一次输出的顺序排列
+type Result struct {
+	Files       []*FileResult
+	Diagnostics []Diagnostic
+}
+
+// file 返回 path 对应的文件，不存在时追加一个
+func (r *Result) file(path string) *FileResult {
+	for _, f := range r.Files {
+		if f.Path == path {
+			return f
+		}
+	}
+	f := &FileResult{Path: path}
+	r.Files = append(r.Files, f)
+	return f
+}
+
+// write 记录文件生成后的内容。每个文件在所有拷贝函数生成之后只渲染一次
+func (r *Result) write(path string, content []byte) {
+	r.file(path).Content = content
+}
+
+// has 判断 path 是否已经有输出
+func (r *Result) has(path string) bool {
+	for _, f := range r.Files {
+		if f.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// remove 记录需要删除的文件
+func (r *Result) remove(path string) {
+	f := r.file(path)
+	f.Content = nil
+	f.Removed = true
+}
+
+// addFunc 记录生成到 path 中的顶层拷贝函数
+func (r *Result) addFunc(path string, pos token.Position, decl *ast.FuncDecl, fields []FieldMapping) {
+	f := r.file(path)
+	f.Funcs = append(f.Funcs, GeneratedFunc{
+		Name:      decl.Name.Name,
+		Pos:       pos,
+		Signature: funcSignature(decl),
+		Fields:    fields,
+	})
+}
+
+func (r *Result) warnf(pos token.Position, format string, args ...any) {
+	r.addDiagnostic(Diagnostic{Pos: pos, Severity: SeverityWarning, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *Result) errorf(pos token.Position, format string, args ...any) {
+	r.addDiagnostic(Diagnostic{Pos: pos, Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+}
+
+// addDiagnostic 追加诊断信息，同一个问题（如被多个包导入的转换函数）只记录一次
+func (r *Result) addDiagnostic(d Diagnostic) {
+	for _, old := range r.Diagnostics {
+		if old == d {
+			return
+		}
+	}
+	r.Diagnostics = append(r.Diagnostics, d)
+}
+
+// markChanged 和磁盘上的内容比较，设置每个文件的 Changed
+func (r *Result) markChanged() error {
+	for _, f := range r.Files {
+		if f.Removed {
+			_, err := os.Stat(f.Path)
+			f.Changed = err == nil
+			continue
+		}
+		if f.Content == nil {
+			continue
+		}
+		old, err := os.ReadFile(f.Path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		f.Changed = !bytes.Equal(old, f.Content)
+	}
+	return nil
+}
+
+// skipFailed 把有 SeverityError 级别诊断的包（按目录）中的文件标记为不需要写入
+func (r *Result) skipFailed() {
+	failed := make(map[string]bool)
+	for _, d := range r.Diagnostics {
+		if d.Severity == SeverityError && d.Pos.Filename != "" {
+			failed[filepath.Dir(d.Pos.Filename)] = true
+		}
+	}
+	for _, f := range r.Files {
+		if failed[filepath.Dir(f.Path)] {
+			f.Changed = false
+		}
+	}
+}
+
+// Changed 返回需要写入的文件
+func (r *Result) Changed() []*FileResult {
+	var files []*FileResult
+	for _, f := range r.Files {
+		if f.Changed {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// Err 把所有 SeverityError 级别的诊断合并成一个 error，没有时返回 nil
+func (r *Result) Err() error {
+	var errs []error
+	for _, d := range r.Diagnostics {
+		if d.Severity == SeverityError {
+			errs = append(errs, errors.New(d.String()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Write 把有变化的文件写回磁盘，删除不再需要的生成文件，返回这些文件。
+// 先把所有文件写到各自目录下的临时文件，全部成功后再依次重命名和删除，
+// 写临时文件失败时所有文件都保持不变。重命名本身出错（很少见）时，已经重命名的文件不会恢复
+func (r *Result) Write() ([]string, error) {
+	changed := r.Changed()
+	tmps := make([]string, len(changed))
+	cleanup := func() {
+		for _, tmp := range tmps {
+			if tmp != "" {
+				os.Remove(tmp)
+			}
+		}
+	}
+	for i, f := range changed {
+		if f.Removed {
+			continue
+		}
+		tmp, err := writeTemp(f.Path, f.Content)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		tmps[i] = tmp
+	}
+
+	var paths []string
+	for i, f := range changed {
+		var err error
+		if f.Removed {
+			err = os.Remove(f.Path)
+		} else {
+			err = os.Rename(tmps[i], f.Path)
+		}
+		if err != nil {
+			cleanup()
+			return paths, err
+		}
+		tmps[i] = ""
+		paths = append(paths, f.Path)
+	}
+	return paths, nil
+}
+
+// writeTemp 把 content 写到 path 旁边的临时文件并返回它，之后重命名为 path 即可替换原文件。
+// 已有的文件保留原来的权限。中途失败时删除临时文件
+func writeTemp(path string, content []byte) (name string, err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	perm := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if _, err := tmp.Write(content); err != nil {
+		return "", err
+	}
+	if err := tmp.Sync(); err != nil {
+		return "", err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// parsePosition 解析 packages.Error 中 file:line:col 形式的位置
+func parsePosition(s string) token.Position {
+	var pos token.Position
+	rest := s
+	var nums []int
+	for i := 0; i < 2; i++ {
+		j := strings.LastIndexByte(rest, ':')
+		if j < 0 {
+			break
+		}
+		n, err := strconv.Atoi(rest[j+1:])
+		if err != nil {
+			break
+		}
+		nums = append([]int{n}, nums...)
+		rest = rest[:j]
+	}
+	if s == "" || s == "-" {
+		return pos
+	}
+	pos.Filename = rest
+	if len(nums) > 0 {
+		pos.Line = nums[0]
+	}
+	if len(nums) > 1 {
+		pos.Column = nums[1]
+	}
+	return pos
+}
@@ -0,0 +1,347 @@
+package quickcopy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Options 是 // :quickcopy 指令的选项，命令行可以为所有拷贝函数设置默认值
+type Options struct {
+	AllowNarrow   bool // --allow-narrow
+	IgnoreCase    bool // --ignore-case
+	SingleToSlice bool // --single-to-slice
+	JoinErrors    bool // --join-errors
+	Strict        bool // --strict
+	StrictSource  bool // --strict-source
+	Flatten       bool // --flatten
+
+	MatchTags []string // --match-tag=json,db
+	Match     string   // --match=normalized
+	Strip     []string // --strip=Src,DTO,PB
+}
+
+// Config 控制生成的范围和默认选项
+type Config struct {
+	Dir      string   // 在这个目录下解析包，默认当前目录
+	Patterns []string // go list 风格的包，如 ./...、./internal/dto，默认 ./...
+	Tags     []string // 构建标签
+	Exclude  []string // 不处理的文件或目录，相对 Dir 的 glob，如 internal/legacy、*_mock.go
+	Verbose  bool     // 输出生成过程的详细日志
+	Defaults Options  // 所有拷贝函数的默认选项
+}
+
+func (c *Config) dir() string {
+	if c.Dir == "" {
+		return "."
+	}
+	return c.Dir
+}
+
+// relPath 返回相对 Dir 的路径，用于输出
+func (c *Config) relPath(p string) string {
+	absDir, err := filepath.Abs(c.dir())
+	if err != nil {
+		return p
+	}
+	rel, err := filepath.Rel(absDir, p)
+	if err != nil {
+		return p
+	}
+	return filepath.ToSlash(rel)
+}
+
+// excluded 判断文件是否被 Exclude 排除，模式可以匹配相对路径、任意一级上层目录或者文件名
+func (c *Config) excluded(file string) bool {
+	if len(c.Exclude) == 0 {
+		return false
+	}
+	rel := c.relPath(file)
+	for _, pattern := range c.Exclude {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+			return true
+		}
+		for p := rel; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// logWarnings 把警告输出到日志，错误由 Result.Err 返回给调用者
+func logWarnings(res *Result) {
+	for _, d := range res.Diagnostics {
+		if d.Severity == SeverityWarning {
+			log.Print(d)
+		}
+	}
+}
+
+func Main(dir string) {
+	if _, err := Run(Config{Dir: dir, Verbose: true}, false); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Run 生成拷贝函数并写回有变化的文件，返回这些文件。dryRun 为 true 时只返回不写入。
+// 诊断信息输出到日志，有 SeverityError 级别的诊断时返回 error。出错的包中的文件都不写入，
+// 以免删除或者覆盖包里还在使用的代码，其他包的文件仍然会写入
+func Run(cfg Config, dryRun bool) ([]string, error) {
+	res, err := Generate(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	logWarnings(res)
+	res.skipFailed()
+
+	var paths []string
+	if dryRun {
+		for _, f := range res.Changed() {
+			paths = append(paths, f.Path)
+		}
+	} else if paths, err = res.Write(); err != nil {
+		return paths, err
+	}
+	return paths, res.Err()
+}
+
+// Check 执行和 Main 相同的生成流程，但不修改任何文件。
+// 生成结果和磁盘上不一致的文件以 unified diff 的格式输出到 w，返回是否有文件需要重新生成，
+// 生成失败时同样返回 true
+func Check(dir string, w io.Writer) bool {
+	stale, err := Diff(Config{Dir: dir, Verbose: true}, w)
+	if err != nil {
+		log.Print(err)
+		return true
+	}
+	return stale
+}
+
+// Diff 和 Check 相同，使用 cfg 指定的包和选项
+func Diff(cfg Config, w io.Writer) (bool, error) {
+	res, err := Generate(context.Background(), cfg)
+	if err != nil {
+		return false, err
+	}
+	logWarnings(res)
+
+	stale := false
+	for _, f := range res.Changed() {
+		old, _ := os.ReadFile(f.Path)
+		name := cfg.relPath(f.Path)
+		oldName := "a/" + name
+		if old == nil {
+			oldName = "/dev/null"
+		}
+		newName := "b/" + name
+		if f.Removed {
+			newName = "/dev/null"
+		}
+		stale = true
+		io.WriteString(w, unifiedDiff(oldName, newName, string(old), string(f.Content)))
+	}
+	return stale, res.Err()
+}
+
+// Explain 输出每个拷贝函数的字段映射，不修改任何文件
+func Explain(cfg Config, w io.Writer) error {
+	res, err := Generate(context.Background(), cfg)
+	if err != nil {
+		return err
+	}
+	logWarnings(res)
+
+	for _, file := range res.Files {
+		for _, f := range file.Funcs {
+			fmt.Fprintf(w, "%s:%d: %s\n", cfg.relPath(f.Pos.Filename), f.Pos.Line, f.Signature)
+			for _, m := range f.Fields {
+				dst, src := m.DstField, m.SrcField
+				if m.IsSlice {
+					dst, src = "*dst", "*src"
+				}
+				if m.Expr != "" {
+					src = m.Expr
+				}
+				fmt.Fprintf(w, "\t%s <- %s%s\n", dst, src, explainConversion(m.Conversion))
+			}
+		}
+	}
+	return res.Err()
+}
+
+// funcSignature 返回函数声明去掉函数体和注释后的写法
+func funcSignature(decl *ast.FuncDecl) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, token.NewFileSet(), &ast.FuncDecl{Name: decl.Name, Type: decl.Type})
+	return buf.String()
+}
+
+// explainConversion 返回字段使用的转换函数，函数字面量只保留签名
+func explainConversion(conv string) string {
+	if conv == "" {
+		return ""
+	}
+	if strings.HasPrefix(conv, "func(") {
+		if i := strings.Index(conv, "{"); i > 0 {
+			conv = strings.TrimSpace(conv[:i]) + " {...}"
+		}
+	}
+	return " (" + conv + ")"
+}
+
+// Clean 删除生成的 zz_quickcopy_gen*.go 文件，返回这些文件。dryRun 为 true 时只返回不删除。
+// 函数形式的原型就地生成的函数体不会被删除，但它们用到的辅助函数在生成文件中，删除后需要重新生成
+func Clean(cfg Config, dryRun bool) ([]string, error) {
+	s := newSession(cfg)
+	files, err := s.loadSourceFiles(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	logWarnings(s.res)
+
+	var removed []string
+	for _, src := range files {
+		if !isGenFile(src.path) {
+			continue
+		}
+		if !bytes.HasPrefix(src.content, []byte(genFileHeader)) {
+			log.Printf("Skipping %s: not generated by quickcopy", src.path)
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(src.path); err != nil {
+				return removed, err
+			}
+		}
+		removed = append(removed, src.path)
+	}
+	return removed, nil
+}
+
+// Generate 加载 cfg 指定的包，生成所有拷贝函数，结果保存在内存中，不修改任何文件。
+// 单个拷贝函数的问题记录在 Result.Diagnostics 中，不影响其他函数；
+// 只有加载包失败、读取文件失败或者 ctx 取消时返回 error。
+// 每次调用使用独立的 session，可以在同一个进程中多次或者并发调用
+func Generate(ctx context.Context, cfg Config) (*Result, error) {
+	s := newSession(cfg)
+	res := s.res
+
+	// 一次性加载所有包及其类型信息
+	files, err := s.loadSourceFiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load packages: %w", err)
+	}
+	for _, src := range files {
+		if isGenFile(src.path) && bytes.HasPrefix(src.content, []byte(genFileHeader)) {
+			s.scope(src).prev = src
+		}
+	}
+
+	envs := make(map[*sourceFile]*typeEnv)
+	for _, src := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		s.debugf("Processing file: %s", src.path)
+		// 测试文件可以使用非测试文件生成的辅助函数，反过来不行。包内非测试文件的原型要在测试文件之前生成，
+		// 否则它们用到的辅助函数可能先在测试文件的生成文件中生成，两个生成文件里各有一份
+		if strings.HasSuffix(src.path, "_test.go") {
+			if p := s.scopes[src.pkg.PkgPath]; p != nil && p.gen != nil {
+				s.generateStubs(p.gen)
+			}
+		}
+		env := s.newTypeEnv(src)
+		file := src.file
+
+		// 查找带有 // :quickcopy 注释的函数
+		ast.Inspect(file, func(n ast.Node) bool {
+			// var _ func(dst *D, src *S) = CopyD 形式的原型，函数生成到单独的文件中
+			if genDecl, ok := n.(*ast.GenDecl); ok {
+				for _, stub := range s.findCopyStubs(genDecl, src) {
+					g := s.genFile(src)
+					g.stubs = append(g.stubs, stub)
+				}
+				return false
+			}
+
+			// 查找函数声明
+			funcDecl, ok := n.(*ast.FuncDecl)
+			if !ok || funcDecl.Doc == nil {
+				return true
+			}
+
+			// 检查是否有 // :quickcopy 注释
+			d, ok := parseDirective(funcDecl.Doc, cfg.Defaults)
+			if !ok {
+				return true
+			}
+
+			s.debugf("Found // :quickcopy function: %s", funcDecl.Name.Name)
+			env.pos = src.pkg.Fset.Position(funcDecl.Pos())
+
+			// 通过类型信息解析函数签名
+			fn, _ := src.pkg.TypesInfo.Defs[funcDecl.Name].(*types.Func)
+			if fn == nil {
+				res.errorf(env.pos, "no type information for function %s", funcDecl.Name.Name)
+				return true
+			}
+
+			// 生成失败时保留原来的函数体，它用到的辅助函数也要保留
+			env.scope.roots = append(env.scope.roots, funcDecl)
+			_, fields, err := generateTopLevelFunc(funcDecl, funcDecl.Name.Name, fn.Type().(*types.Signature), d, env)
+			if err != nil {
+				res.errorf(env.pos, "%v", err)
+				return true
+			}
+			res.addFunc(src.path, env.pos, funcDecl, fields)
+			src.changed[funcDecl] = true
+			return true
+		})
+
+		envs[src] = env
+	}
+
+	// 辅助函数要等所有拷贝函数都生成之后才能确定，最后统一写入生成文件
+	for i := 0; i < len(s.genFiles); i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		s.generateStubs(s.genFiles[i])
+	}
+	s.markReachable()
+	for _, g := range s.genFiles {
+		s.writeGenFile(g)
+	}
+	// 将修改后的 AST 连同必要的导入写回文件，以前的版本追加的辅助函数一并删除。
+	// 生成文件中有哪些辅助函数确定之后才能写入，和它们同名的函数也要删除
+	for _, src := range files {
+		helpers := s.emittedHelpers(src)
+		if len(src.changed) > 0 || hasStaleHelpers(src, helpers) {
+			s.writeFile(src, envs[src], helpers)
+		}
+	}
+	// 原型和辅助函数都不再需要的生成文件直接删除。有文件没有加载的包可能还在使用，不删除
+	for _, src := range files {
+		if s.scope(src).prev == src && !res.has(src.path) && !s.partial[filepath.Dir(src.path)] {
+			res.remove(src.path)
+		}
+	}
+
+	if err := res.markChanged(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,252 @@
+package quickcopy
+
+import (
+	"go/ast"
+	"go/types"
+	"log"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// session 保存一次生成的全部状态：诊断信息、转换函数表以及每个包已经生成的函数。
+// 每次 Generate 使用新的 session，不同的 session 之间不共享状态，可以并发调用
+type session struct {
+	cfg     Config
+	res     *Result
+	verbose bool // 输出生成过程的详细日志
+
+	converters map[string]map[string]*converter // 包 ID -> 转换函数表（类型对 -> 转换函数）
+	scopes     map[string]*pkgState             // scopeKey -> 包的生成状态
+	genFiles   []*genFile                       // 按创建顺序排列的生成文件
+	partial    map[string]bool                  // 有文件被排除或者没有加载的包所在的目录
+}
+
+func newSession(cfg Config) *session {
+	return &session{
+		cfg:        cfg,
+		res:        &Result{},
+		verbose:    cfg.Verbose,
+		converters: make(map[string]map[string]*converter),
+		scopes:     make(map[string]*pkgState),
+		partial:    make(map[string]bool),
+	}
+}
+
+// debugf 输出生成过程的详细日志，警告记录在 Result 中
+func (s *session) debugf(format string, args ...any) {
+	if s.verbose {
+		log.Printf(format, args...)
+	}
+}
+
+// pkgState 是一个包中已经生成的函数。辅助函数在包内共用，只生成一次，全部写到包的生成文件中；
+// 包内测试文件的 pkgState 以非测试文件的为 parent，可以使用后者生成的函数，反过来不行
+type pkgState struct {
+	parent *pkgState
+	gen    *genFile    // 包的生成文件，第一次需要时创建
+	prev   *sourceFile // 磁盘上已有的生成文件，这次没有生成的函数可以从中保留
+
+	structPairs map[string]conversion    // helperKey -> 结构体拷贝函数的调用方式
+	topLevel    map[string]string        // helperKey -> 顶层拷贝函数名，嵌套字段遇到相同的类型对时直接调用
+	helpers     map[string]*ast.FuncDecl // 包内已经生成的辅助函数
+	roots       []*ast.FuncDecl          // 包内的顶层拷贝函数，从这里开始标记用到的辅助函数
+	reachable   map[string]bool          // 顶层拷贝函数直接或者间接调用的辅助函数
+}
+
+func newPkgState(parent *pkgState) *pkgState {
+	return &pkgState{
+		parent:      parent,
+		structPairs: make(map[string]conversion),
+		topLevel:    make(map[string]string),
+		helpers:     make(map[string]*ast.FuncDecl),
+		reachable:   make(map[string]bool),
+	}
+}
+
+// scope 返回源文件所在包的生成状态。测试文件和非测试文件分开，
+// 这样非测试文件不会用到只在测试文件中生成的辅助函数
+func (s *session) scope(src *sourceFile) *pkgState {
+	key := src.pkg.PkgPath
+	if !strings.HasSuffix(src.path, "_test.go") {
+		return s.pkgScope(key, nil)
+	}
+	return s.pkgScope(key+" [test]", s.pkgScope(key, nil))
+}
+
+func (s *session) pkgScope(key string, parent *pkgState) *pkgState {
+	p, ok := s.scopes[key]
+	if !ok {
+		p = newPkgState(parent)
+		s.scopes[key] = p
+	}
+	return p
+}
+
+// genFile 返回源文件所在包的生成文件
+func (s *session) genFile(src *sourceFile) *genFile {
+	p := s.scope(src)
+	if p.gen == nil {
+		path := genFilePath(src)
+		// 生成文件不使用源文件里的导入别名
+		env := s.newTypeEnv(src)
+		env.file = nil
+		env.path = path
+		p.gen = &genFile{path: path, src: src, env: env}
+		s.genFiles = append(s.genFiles, p.gen)
+	}
+	return p.gen
+}
+
+// structPair 查找已经生成（或正在生成）的结构体拷贝函数
+func (p *pkgState) structPair(key string) (conversion, bool) {
+	for ; p != nil; p = p.parent {
+		if conv, ok := p.structPairs[key]; ok {
+			return conv, true
+		}
+	}
+	return conversion{}, false
+}
+
+// topLevelFunc 查找类型对相同的顶层拷贝函数
+func (p *pkgState) topLevelFunc(key string) (string, bool) {
+	for ; p != nil; p = p.parent {
+		if name, ok := p.topLevel[key]; ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// helper 查找已经生成的辅助函数，返回它所在的 pkgState
+func (p *pkgState) helper(name string) (*pkgState, *ast.FuncDecl) {
+	for ; p != nil; p = p.parent {
+		if fn, ok := p.helpers[name]; ok {
+			return p, fn
+		}
+	}
+	return nil, nil
+}
+
+// hasHelper 判断辅助函数是否已经生成
+func (p *pkgState) hasHelper(name string) bool {
+	owner, _ := p.helper(name)
+	return owner != nil
+}
+
+// prevFunc 查找已有的生成文件中的函数
+func (p *pkgState) prevFunc(name string) *ast.FuncDecl {
+	if p.prev == nil {
+		return nil
+	}
+	for _, decl := range p.prev.file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name {
+			return fn
+		}
+	}
+	return nil
+}
+
+// markReachable 从所有顶层拷贝函数出发标记用到的辅助函数。生成时尝试过但最终没有用到的、
+// 字段类型改变或者顶层拷贝函数删除之后不再需要的辅助函数都不会写入生成文件。
+// 有文件被排除或者没有加载的包无法知道哪些函数还在使用，已有的生成文件中的函数全部保留
+func (s *session) markReachable() {
+	keys := make([]string, 0, len(s.scopes))
+	for key := range s.scopes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		p := s.scopes[key]
+		if p.prev != nil && s.partial[filepath.Dir(p.prev.path)] {
+			for _, decl := range p.prev.file.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+					s.keep(p, fn.Name.Name)
+				}
+			}
+		}
+		for _, root := range p.roots {
+			s.mark(p, root)
+		}
+	}
+}
+
+// mark 标记 fn 中调用的辅助函数，以及这些辅助函数调用的辅助函数
+func (s *session) mark(p *pkgState, fn *ast.FuncDecl) {
+	if fn.Body == nil {
+		return
+	}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			s.keep(p, id.Name)
+		}
+		return true
+	})
+}
+
+// keep 标记 p 中可以使用的辅助函数 name。这次没有生成的（如生成失败时保留的旧函数体用到的），
+// 从已有的生成文件中保留
+func (s *session) keep(p *pkgState, name string) {
+	owner, helper := p.helper(name)
+	if helper == nil {
+		owner, helper = s.prevHelper(p, name)
+	}
+	if helper == nil || owner.reachable[name] {
+		return
+	}
+	owner.reachable[name] = true
+	s.mark(owner, helper)
+}
+
+// prevHelper 在 p 以及 parent 已有的生成文件中查找这次没有生成的辅助函数，找到后作为辅助函数加入所在的包。
+// 这次重新生成的原型对应的拷贝函数不算
+func (s *session) prevHelper(p *pkgState, name string) (*pkgState, *ast.FuncDecl) {
+	for ; p != nil; p = p.parent {
+		fn := p.prevFunc(name)
+		if fn == nil {
+			continue
+		}
+		g := s.genFile(p.prev)
+		if slices.ContainsFunc(g.funcs, func(f *ast.FuncDecl) bool { return f.Name.Name == name }) {
+			return nil, nil
+		}
+		fn = s.adopt(g, fn)
+		p.addHelper(name, fn)
+		return p, fn
+	}
+	return nil, nil
+}
+
+// adopt 把已有的生成文件中的函数放到 g 中重新输出，引用的包按 g 的导入重新决定包名
+func (s *session) adopt(g *genFile, fn *ast.FuncDecl) *ast.FuncDecl {
+	prev := g.env.scope.prev
+	ast.Inspect(fn, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			if pkgName, ok := prev.pkg.TypesInfo.Uses[id].(*types.PkgName); ok {
+				id.Name = g.env.qualifier(pkgName.Imported())
+			}
+		}
+		return true
+	})
+	clearPositions(fn)
+	return fn
+}
+
+// emittedHelpers 返回源文件所在的包（包括测试文件）的生成文件中输出的辅助函数
+func (s *session) emittedHelpers(src *sourceFile) map[string]bool {
+	helpers := make(map[string]bool)
+	for _, key := range []string{src.pkg.PkgPath, src.pkg.PkgPath + " [test]"} {
+		if p := s.scopes[key]; p != nil {
+			for name := range p.reachable {
+				helpers[name] = true
+			}
+		}
+	}
+	return helpers
+}
+
+// addHelper 记录新生成的辅助函数
+func (p *pkgState) addHelper(name string, fn *ast.FuncDecl) {
+	p.helpers[name] = fn
+}
@@ -5,94 +5,60 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
-	"log"
+	"go/types"
 )
 
-func generateBasicSliceCopyFunc(srcElem, dstElem string) (string, string) {
-	funcName := getSliceCopyFuncName(srcElem, dstElem)
-
-	// 如果元素类型相同，直接返回浅拷贝
-	if srcElem == dstElem {
-		return fmt.Sprintf("func(src []%s) []%s { return append([]%s(nil), src...) }", srcElem, dstElem, dstElem), ""
-	}
-
-	// 使用 LoadOrStore 确保并发安全
-	if _, loaded := generatedFunctions.Load(funcName); loaded {
-		log.Printf("Slice function %s already generated", funcName)
-		return funcName, ""
-	}
-
-	// 生成基本类型之间的转换函数
-	code0, importPath := handleBasicConversion(srcElem, dstElem, true)
-	code := fmt.Sprintf(`
-    package main
-    // %s 是自动生成的切片拷贝函数
-    func %s(src []%s) []%s {
-        if src == nil {
-            return nil
-        }
-        dst := make([]%s, len(src))
-        for i := range src {
-            dst[i] = %s(src[i])
-        }
-        return dst
-    }`, funcName, funcName, srcElem, dstElem, dstElem, code0)
-
-	// 安全解析生成的代码
-	fset := token.NewFileSet()
-	parsedFile, err := parser.ParseFile(fset, "", code, parser.ParseComments)
-	if err != nil {
-		log.Printf("Failed to parse generated slice function: %v", err)
-		return "", ""
-	}
-
-	if len(parsedFile.Decls) == 0 {
-		log.Printf("Generated slice function is empty")
-		return "", ""
-	}
+func isSliceType(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Slice)
+	return ok
+}
 
-	if fn, ok := parsedFile.Decls[0].(*ast.FuncDecl); ok {
-		addGeneratedFunction(funcName, fn)
-		return funcName, importPath
+// getElementType 获取切片或数组的元素类型
+func getElementType(t types.Type) types.Type {
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		return u.Elem()
+	case *types.Array:
+		return u.Elem()
 	}
-	return "", ""
+	return t
 }
 
-func generateSliceCopyFunc(srcElem, dstElem, elemConv string, file *ast.File, path string) (string, string) {
+func handleSliceConversion(srcType, dstType types.Type, opts copyOptions, mode errMode, env *typeEnv) (conversion, bool) {
+	// 元素转换也写在生成文件的辅助函数中
+	env = env.helperEnv()
+	srcElem := getElementType(srcType)
+	dstElem := getElementType(dstType)
 
-	// 基本类型直接转换
-	if isBasicType(srcElem) && isBasicType(dstElem) {
-		return generateBasicSliceCopyFunc(srcElem, dstElem)
+	// 生成元素转换函数
+	elemConv, ok := getTypeConversion(srcElem, dstElem, opts, mode, env)
+	if !ok {
+		env.debugf("No element conversion for %s to %s", env.typeString(srcType), env.typeString(dstType))
+		return conversion{}, false
 	}
 
-	// 强制生成元素类型的转换函数
-	generateCopyFunctionIfNeeded(srcElem, dstElem, file, path)
-
-	funcName := getSliceCopyFuncName(srcElem, dstElem)
-
-	// 如果元素类型相同，直接返回浅拷贝
-	if srcElem == dstElem {
-		return fmt.Sprintf("func(src []%s) []%s { return append([]%s(nil), src...) }", srcElem, dstElem, dstElem), ""
+	funcName := generateSliceCopyFunc(srcElem, dstElem, elemConv, mode, env)
+	if funcName == "" {
+		return conversion{}, false
 	}
-	// 使用 LoadOrStore 确保并发安全
-	if _, loaded := generatedFunctions.Load(funcName); loaded {
-		log.Printf("Slice function %s already generated", funcName)
-		return funcName, ""
+	if elemConv.Fallible {
+		return conversion{Func: funcName, Fallible: true, ErrSep: errSepNested, Opts: elemConv.Opts}, true
 	}
+	return conversion{Func: funcName, Opts: elemConv.Opts}, true
+}
 
-	// 处理file为nil的情况
-	srcIsStruct := file != nil && isStructType(srcElem, file, path)
-	dstIsStruct := file != nil && isStructType(dstElem, file, path)
-
-	// 如果源和目标元素都不是结构体，生成直接拷贝函数
-	if !srcIsStruct && !dstIsStruct {
-		return fmt.Sprintf("func(src []%s) []%s { return src }", srcElem, dstElem), ""
+func generateSliceCopyFunc(srcElem, dstElem types.Type, elemConv conversion, mode errMode, env *typeEnv) string {
+	srcElemName := env.typeString(srcElem)
+	dstElemName := env.typeString(dstElem)
+	// 元素用到的结构体拷贝函数带选项后缀时，切片拷贝函数也带上
+	funcName := getSliceCopyFuncName(srcElemName, dstElemName) + elemConv.Opts
+	if elemConv.Fallible {
+		funcName += mode.suffix()
 	}
 
-	// 需要转换函数时，确保elemConv非空
-	if elemConv == "" {
-		log.Printf("elemConv is required for struct elements but is empty")
-		return "", ""
+	if env.scope.hasHelper(funcName) {
+		env.debugf("Slice function %s already generated", funcName)
+		return funcName
 	}
 
 	code := fmt.Sprintf(`
@@ -104,48 +70,51 @@ func %s(src []%s) []%s {
 	}
 	dst := make([]%s, len(src))
 	for i := range src {
-		%s(&dst[i], &src[i])
+		%s
 	}
 	return dst
-}`, funcName, funcName, srcElem, dstElem, dstElem, elemConv)
+}`, funcName, funcName, srcElemName, dstElemName, dstElemName,
+		generateElementConversion("src[i]", "dst[i]", elemConv))
+
+	// 元素转换可能失败时返回带下标的错误
+	if elemConv.Fallible {
+		code = fmt.Sprintf(`
+	package main
+// %s 是自动生成的切片拷贝函数
+func %s(src []%s) ([]%s, error) {
+	if src == nil {
+		return nil, nil
+	}
+	dst := make([]%s, len(src))
+	%s
+	for i := range src {
+		if %s; err != nil {
+			%s
+		}
+	}
+	return dst, %s
+}`, funcName, funcName, srcElemName, dstElemName, dstElemName, mode.errDecl(),
+			generateFallibleConversion("src[i]", "dst[i]", elemConv),
+			mode.onErr("nil, ", "[%d]", elemConv, env, "i"),
+			mode.returnErr(env))
+	}
 
 	// 安全解析生成的代码
 	fset := token.NewFileSet()
 	parsedFile, err := parser.ParseFile(fset, "", code, parser.ParseComments)
 	if err != nil {
-		log.Printf("Failed to parse generated slice function: %v", err)
-		return "", ""
+		env.errorf(token.NoPos, "parse generated slice function: %v", err)
+		return ""
 	}
 
 	if len(parsedFile.Decls) == 0 {
-		log.Printf("Generated slice function is empty")
-		return "", ""
+		env.errorf(token.NoPos, "generated slice function is empty")
+		return ""
 	}
 
 	if fn, ok := parsedFile.Decls[0].(*ast.FuncDecl); ok {
-		addGeneratedFunction(funcName, fn)
-		return funcName, ""
+		addGeneratedFunction(funcName, fn, env)
+		return funcName
 	}
-	return "", ""
-}
-func handleSliceConversion(srcType, dstType string, allowNarrow, singleToSlice bool, file *ast.File, path string) (string, string) {
-
-	srcElem := getElementType(srcType)
-	dstElem := getElementType(dstType)
-
-	if isBasicType(srcElem) && isBasicType(dstElem) {
-		return generateBasicSliceCopyFunc(srcElem, dstElem)
-	}
-
-	// 生成元素转换函数
-	elemConv := getStructCopyFuncName(srcElem, dstElem)
-	generateCopyFunctionIfNeeded(srcElem, dstElem, file, path)
-
-	// 只有当元素类型需要转换时才生成切片函数
-	if elemConv != "" {
-		log.Printf("Generating slice conversion function for %s to %s, funcName: %s", srcType, dstType, elemConv)
-		return generateSliceCopyFunc(srcElem, dstElem, elemConv, file, path)
-	}
-	return "", "" // 直接赋值
-
+	return ""
 }
@@ -0,0 +1,126 @@
+package quickcopy
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+const tagName = "quickcopy"
+
+// fieldTag 是字段上的 quickcopy 标签，如 `quickcopy:"name=UserID,conv=toCents"`、`quickcopy:"-"`。
+// 标签跟着类型走，所有涉及这个类型的拷贝函数（包括嵌套生成的辅助函数）都会遵守
+type fieldTag struct {
+	name string // 和另一边的哪个字段对应，为空时使用字段名
+	skip bool   // "-"：不参与拷贝
+	conv string // 转换函数名，签名为 func(A) B 或者 func(A) (B, error)
+
+	raw string // 完整的结构体标签，--match-tag 从中读取 json、db 等标签
+}
+
+// key 返回字段参与匹配时使用的名字
+func (t fieldTag) key(field *types.Var) string {
+	if t.name != "" {
+		return t.name
+	}
+	return field.Name()
+}
+
+// parseFieldTag 解析结构体标签中的 quickcopy 部分
+func parseFieldTag(tag string) (fieldTag, error) {
+	value, ok := reflect.StructTag(tag).Lookup(tagName)
+	if !ok {
+		return fieldTag{}, nil
+	}
+	if value == "-" {
+		return fieldTag{skip: true}, nil
+	}
+
+	var t fieldTag
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(item, "=")
+		switch {
+		case k == "name" && v != "":
+			t.name = v
+		case k == "conv" && v != "":
+			t.conv = v
+		default:
+			return fieldTag{}, fmt.Errorf("invalid %s tag %q", tagName, value)
+		}
+	}
+	return t, nil
+}
+
+// fieldTagAt 返回结构体第 i 个字段的 quickcopy 标签，标签写错时给出警告并忽略
+func fieldTagAt(st *types.Struct, i int, env *typeEnv) fieldTag {
+	tag, err := parseFieldTag(st.Tag(i))
+	if err != nil {
+		env.warnf(st.Field(i).Pos(), "field %s: %v", st.Field(i).Name(), err)
+	}
+	tag.raw = st.Tag(i)
+	return tag
+}
+
+// tagValueName 返回标签 key（如 json）中的名字，忽略 omitempty 等选项，"-" 和空名字返回空字符串
+func tagValueName(raw, key string) string {
+	value, ok := reflect.StructTag(raw).Lookup(key)
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(value, ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// tagConversion 返回 conv 标签指定的转换函数。函数在声明标签的字段所在的包中查找，
+// 其次是当前包；参数和返回值需要和源字段、目标字段的类型兼容
+func tagConversion(name string, owner, srcField, dstField *types.Var, mode errMode, env *typeEnv) (conversion, bool) {
+	var fn *types.Func
+	for _, pkg := range []*types.Package{owner.Pkg(), env.pkg.Types} {
+		if pkg == nil {
+			continue
+		}
+		if f, ok := pkg.Scope().Lookup(name).(*types.Func); ok {
+			fn = f
+			break
+		}
+	}
+	if fn == nil {
+		env.warnf(owner.Pos(), "field %s: conv function %s not found", owner.Name(), name)
+		return conversion{}, false
+	}
+	if fn.Pkg() != env.pkg.Types && !fn.Exported() {
+		env.warnf(owner.Pos(), "field %s: conv function %s is not exported", owner.Name(), fn.FullName())
+		return conversion{}, false
+	}
+
+	src, dst, fallible, ok := converterSignature(fn.Type().(*types.Signature))
+	if !ok {
+		env.warnf(owner.Pos(), "field %s: conv function %s must be func(A) B or func(A) (B, error)", owner.Name(), fn.FullName())
+		return conversion{}, false
+	}
+	if !types.AssignableTo(srcField.Type(), src) || !types.AssignableTo(dst, dstField.Type()) {
+		env.warnf(owner.Pos(), "field %s: conv function %s cannot convert %s to %s", owner.Name(), fn.FullName(),
+			env.typeString(srcField.Type()), env.typeString(dstField.Type()))
+		return conversion{}, false
+	}
+	return env.converterConversion(&converter{name: fn.Name(), pkg: fn.Pkg(), fallible: fallible}, src, dst, mode), true
+}
+
+// fieldConversion 返回源字段到目标字段的转换方式，conv 标签优先，目标字段上的标签优先于源字段
+func fieldConversion(srcField, dstField *types.Var, srcTag, dstTag fieldTag, defaultValue string, opts copyOptions, mode errMode, env *typeEnv) (conversion, bool) {
+	if dstTag.conv != "" {
+		return tagConversion(dstTag.conv, dstField, srcField, dstField, mode, env)
+	}
+	if srcTag.conv != "" {
+		return tagConversion(srcTag.conv, srcField, srcField, dstField, mode, env)
+	}
+	return getFieldConversion(srcField.Type(), dstField.Type(), defaultValue, opts, mode, env)
+}
@@ -1,51 +1,103 @@
 package quickcopy
 
-import "strings"
+import (
+	"fmt"
+	"go/types"
+)
 
-func isBasicType(typeName string) bool {
-	switch typeName {
-	case "string", "int", "int8", "int16", "int32", "int64",
-		"uint", "uint8", "uint16", "uint32", "uint64",
-		"float32", "float64", "bool":
-		return true
-	default:
-		return false
-	}
+// conversion 描述从源类型到目标类型的转换方式
+type conversion struct {
+	Func     string // 转换函数、类型名或函数字面量，为空表示直接赋值
+	IsStruct bool   // Func 是形如 copyXFromY(dst *X, src *Y) 的结构体拷贝函数
+	Fallible bool   // Func 额外返回 error，结构体拷贝函数则只返回 error
+	ErrSep   string // Fallible 时字段路径和 Func 返回的错误之间的分隔符
+	Opts     string // 用到的结构体拷贝函数的选项后缀，外层的切片、数组、map 拷贝函数名沿用
 }
 
-func isIntegerType(typeName string) bool {
-	switch typeName {
-	case "int", "int8", "int16", "int32", "int64",
-		"uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
-		return true
-	default:
-		return false
-	}
+func isBasicType(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&(types.IsBoolean|types.IsNumeric|types.IsString) != 0
+}
+
+func isIntegerType(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&(types.IsInteger|types.IsFloat) != 0
 }
 
 // getIntWidth 获取整数类型的宽度
-func getIntWidth(typeName string) int {
-	switch typeName {
-	case "int8", "uint8":
+func getIntWidth(t types.Type) int {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return 0
+	}
+	switch b.Kind() {
+	case types.Int8, types.Uint8:
 		return 8
-	case "int16", "uint16":
+	case types.Int16, types.Uint16:
 		return 16
-	case "int32", "uint32":
+	case types.Int32, types.Uint32:
 		return 32
-	case "int64", "uint64":
+	case types.Int64, types.Uint64:
 		return 64
-	case "int", "uint":
+	case types.Int, types.Uint, types.Uintptr:
 		// 假设 int 和 uint 是 64 位的
 		return 64
-	case "float32":
+	case types.Float32:
 		return 32
-	case "float64":
+	case types.Float64:
 		return 64
 	default:
 		return 0
 	}
 }
 
+// isStructType 判断给定类型的底层类型是否为结构体（time.Time 按基础值处理）
+func isStructType(t types.Type) bool {
+	if isNamedType(t, "time", "Time") {
+		return false
+	}
+	return findStructDef(t) != nil
+}
+
+// 新增指针类型判断函数
+func isPointerType(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Pointer)
+	return ok
+}
+
+// isNamedType 判断 t 是否为 pkgPath 包中名为 name 的具名类型
+func isNamedType(t types.Type, pkgPath, name string) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkgPath && obj.Name() == name
+}
+
+// typeKey 返回 handleSpecialTypeConversion 使用的类型名。
+// exact 为 false 表示 t 只是底层类型与之相同（比如 type UserID int64），需要先做一次类型转换
+func typeKey(t types.Type) (key string, exact bool) {
+	switch {
+	case isNamedType(t, "time", "Time"):
+		return "time.Time", true
+	case isNamedType(t, "github.com/google/uuid", "UUID"):
+		return "uuid.UUID", true
+	}
+
+	_, named := types.Unalias(t).(*types.Named)
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		// byte/rune 等别名统一成 uint8/int32
+		return types.Typ[u.Kind()].Name(), !named
+	case *types.Slice:
+		if b, ok := u.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Uint8 {
+			return "[]byte", !named
+		}
+	}
+	return "", false
+}
+
 // TOOD 加unsafe开关
 func handleSpecialTypeConversion(srcType, dstType string) (code string, importPath string) {
 	switch {
@@ -61,6 +113,10 @@ func handleSpecialTypeConversion(srcType, dstType string) (code string, importPa
 		return "fmt.Sprint", "fmt"
 	case srcType == "string" && dstType == "int":
 		return "func(s string) int { i, _ := strconv.Atoi(s); return i }", "strconv"
+	case srcType == "int64" && dstType == "string":
+		return "func(i int64) string { return strconv.FormatInt(i, 10) }", "strconv"
+	case srcType == "string" && dstType == "int64":
+		return "func(s string) int64 { i, _ := strconv.ParseInt(s, 10, 64); return i }", "strconv"
 	case srcType == "time.Time" && dstType == "string":
 		return "func(t time.Time) string { return t.Format(time.RFC3339) }", "time"
 	case srcType == "string" && dstType == "time.Time":
@@ -73,21 +129,52 @@ func handleSpecialTypeConversion(srcType, dstType string) (code string, importPa
 	return "", ""
 }
 
-func isSliceOrArray(t string) bool {
-	return strings.Contains(t, "[") || strings.HasPrefix(t, "[]")
-}
+// handleSpecialConversion 通过 handleSpecialTypeConversion 查表转换，
+// 并为底层类型相同的具名类型补上前后的类型转换。
+// 拷贝函数返回 error 时优先使用 handleFallibleTypeConversion 中会返回错误的版本
+func handleSpecialConversion(src, dst types.Type, mode errMode, env *typeEnv) (conversion, bool) {
+	srcKey, srcExact := typeKey(src)
+	dstKey, dstExact := typeKey(dst)
 
-// getElementType 获取容器类型的元素类型
-func getElementType(containerType string) string {
-	// 处理数组类型（如 [3]int → int）
-	if strings.Contains(containerType, "]") {
-		return containerType[strings.Index(containerType, "]")+1:]
+	var code, importPath string
+	fallible := false
+	if mode != errIgnore {
+		code, importPath = handleFallibleTypeConversion(srcKey, dstKey)
+		fallible = code != ""
+	}
+	if code == "" {
+		code, importPath = handleSpecialTypeConversion(srcKey, dstKey)
+	}
+	if code == "" {
+		return conversion{}, false
 	}
+	env.addImport(importPath)
 
-	// 处理切片类型（如 []int → int）
-	if strings.HasPrefix(containerType, "[]") {
-		return strings.TrimPrefix(containerType, "[]")
+	conv := conversion{Func: code}
+	if fallible {
+		conv.Fallible = true
+		conv.ErrSep = errSepLeaf
+	}
+	if srcExact && dstExact {
+		return conv, true
 	}
 
-	return containerType
+	arg := "v"
+	if !srcExact {
+		arg = fmt.Sprintf("%s(v)", srcKey)
+	}
+	result := fmt.Sprintf("%s(%s)", code, arg)
+	switch {
+	case !fallible && !dstExact:
+		result = fmt.Sprintf("%s(%s)", env.typeString(dst), result)
+	case fallible && !dstExact:
+		conv.Func = fmt.Sprintf("func(v %s) (%s, error) { r, err := %s; return %s(r), err }",
+			env.typeString(src), env.typeString(dst), result, env.typeString(dst))
+		return conv, true
+	case fallible:
+		conv.Func = fmt.Sprintf("func(v %s) (%s, error) { return %s }", env.typeString(src), env.typeString(dst), result)
+		return conv, true
+	}
+	conv.Func = fmt.Sprintf("func(v %s) %s { return %s }", env.typeString(src), env.typeString(dst), result)
+	return conv, true
 }
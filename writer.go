@@ -0,0 +1,191 @@
+package quickcopy
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// declStart 返回声明（连同文档注释）的起始位置
+func declStart(fn *ast.FuncDecl) token.Pos {
+	if fn.Doc != nil {
+		return fn.Doc.Pos()
+	}
+	return fn.Pos()
+}
+
+// renderFile 把生成的代码拼接回原始源码。
+// 未修改的声明按原文保留，顶层拷贝函数只替换函数体，以前的版本追加到文件中的辅助函数整体删除，
+// 最后删除不再使用的导入、补充需要的导入并格式化。
+// 这样不会出现 go/printer 把注释挪到别的函数里的问题 (https://github.com/golang/go/issues/20744)。
+// helpers 是包的生成文件中输出的辅助函数
+func renderFile(src *sourceFile, imports []string, helpers map[string]bool) ([]byte, error) {
+	fset := src.pkg.Fset
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+
+	var buf bytes.Buffer
+	last := 0
+	var removed []posRange // 被替换或者删除的代码
+	for _, decl := range src.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+
+		if src.changed[fn] {
+			buf.Write(src.content[last:offset(fn.Body.Lbrace)])
+			if err := format.Node(&buf, token.NewFileSet(), &ast.BlockStmt{List: fn.Body.List}); err != nil {
+				return nil, err
+			}
+			last = offset(fn.Body.Rbrace) + 1
+			removed = append(removed, posRange{fn.Body.Lbrace, fn.Body.Rbrace})
+			continue
+		}
+
+		if isGeneratedHelper(fn, helpers) {
+			buf.Write(src.content[last:offset(declStart(fn))])
+			last = offset(fn.End())
+			removed = append(removed, posRange{declStart(fn), fn.End()})
+		}
+	}
+	buf.Write(src.content[last:])
+
+	return formatWithImports(src.path, buf.Bytes(), imports, nil, staleImports(src, removed, imports))
+}
+
+// posRange 是源码中 [start, end] 之间的一段代码
+type posRange struct {
+	start, end token.Pos
+}
+
+// isGeneratedHelper 判断函数是不是以前的版本追加到源文件中的辅助函数，
+// 这些函数现在都生成到包的生成文件中。以前的版本用 go/printer 输出时可能丢掉或者挪走了文档注释，
+// 和 helpers 中输出到生成文件的辅助函数同名的也是
+func isGeneratedHelper(fn *ast.FuncDecl, helpers map[string]bool) bool {
+	if fn.Recv != nil || fn.Doc != nil && hasDirective(fn.Doc, copyDirective) {
+		return false
+	}
+	if helpers[fn.Name.Name] {
+		return true
+	}
+	if fn.Doc == nil {
+		return false
+	}
+	first := fn.Doc.List[0].Text
+	return strings.HasPrefix(first, "// "+fn.Name.Name+" 是") && strings.Contains(first, "自动生成的")
+}
+
+// hasStaleHelpers 判断源文件中是否有需要删除的辅助函数，生成文件整体覆盖，不用检查
+func hasStaleHelpers(src *sourceFile, helpers map[string]bool) bool {
+	if isGenFile(src.path) {
+		return false
+	}
+	for _, decl := range src.file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && isGeneratedHelper(fn, helpers) {
+			return true
+		}
+	}
+	return false
+}
+
+// staleImports 返回只在 removed 中使用的导入，替换或删除这些代码之后不再需要。
+// keep 是新生成的代码用到的导入
+func staleImports(src *sourceFile, removed []posRange, keep []string) []string {
+	inside := make(map[string]bool)
+	outside := make(map[string]bool)
+	for _, path := range keep {
+		outside[path] = true
+	}
+	for id, obj := range src.pkg.TypesInfo.Uses {
+		pkgName, ok := obj.(*types.PkgName)
+		if !ok || id.Pos() < src.file.Pos() || id.Pos() > src.file.End() {
+			continue
+		}
+		path := pkgName.Imported().Path()
+		if slices.ContainsFunc(removed, func(r posRange) bool { return r.start <= id.Pos() && id.Pos() <= r.end }) {
+			inside[path] = true
+		} else {
+			outside[path] = true
+		}
+	}
+
+	var stale []string
+	for path := range inside {
+		if !outside[path] {
+			stale = append(stale, path)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// renderGenFile 输出生成文件：文件头、拷贝函数以及按名字排序的辅助函数，aliases 中的包带别名导入
+func renderGenFile(pkgName string, funcs []*ast.FuncDecl, helpers map[string]*ast.FuncDecl, imports []string, aliases map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n\npackage %s\n", genFileHeader, pkgName)
+	for _, fn := range funcs {
+		buf.WriteString("\n")
+		if err := writeFuncDecl(&buf, fn); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\n")
+	}
+	if err := writeHelpers(&buf, helpers); err != nil {
+		return nil, err
+	}
+	return formatWithImports("", buf.Bytes(), imports, aliases, nil)
+}
+
+// writeHelpers 按名字顺序输出辅助函数
+func writeHelpers(buf *bytes.Buffer, helpers map[string]*ast.FuncDecl) error {
+	names := make([]string, 0, len(helpers))
+	for name := range helpers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		buf.WriteString("\n")
+		if err := writeFuncDecl(buf, helpers[name]); err != nil {
+			return err
+		}
+		buf.WriteString("\n")
+	}
+	return nil
+}
+
+// formatWithImports 重新解析拼接后的代码，删除 stale 中的导入、补充 imports 中的导入后统一格式化
+func formatWithImports(path string, code []byte, imports []string, aliases map[string]string, stale []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, code, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	deleteImports(fset, file, stale...)
+	addRequiredImports(fset, file, aliases, imports...)
+
+	var out bytes.Buffer
+	if err := format.Node(&out, fset, file); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// writeFuncDecl 输出生成的函数声明，文档注释单独输出以免被 printer 放错位置
+func writeFuncDecl(buf *bytes.Buffer, fn *ast.FuncDecl) error {
+	if fn.Doc != nil {
+		for _, c := range fn.Doc.List {
+			buf.WriteString(c.Text)
+			buf.WriteString("\n")
+		}
+	}
+	decl := *fn
+	decl.Doc = nil
+	return format.Node(buf, token.NewFileSet(), &decl)
+}